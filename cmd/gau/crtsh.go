@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/lc/gau/v2/pkg/crtsh"
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/lc/gau/v2/runner"
+	log "github.com/sirupsen/logrus"
+)
+
+// expandSubdomains queues exact-host work for the subdomains of each domain found
+// in certificate transparency logs. Hosts already queued as input domains are skipped.
+func expandSubdomains(config *providers.Config, domains []string, fetchers []providers.Provider, workChan chan runner.Work) {
+	client := crtsh.New(config)
+
+	seen := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		seen[strings.ToLower(domain)] = struct{}{}
+	}
+
+	for _, domain := range domains {
+		hosts, err := client.Subdomains(strings.ToLower(domain))
+		if err != nil {
+			log.WithField("stage", "crtsh").Warnf("%s - %v", domain, err)
+			continue
+		}
+
+		var queued int
+		for _, host := range hosts {
+			if _, ok := seen[host]; ok {
+				continue
+			}
+			seen[host] = struct{}{}
+			queued++
+			for _, provider := range fetchers {
+				workChan <- runner.NewHostWork(host, provider)
+			}
+		}
+		log.WithField("stage", "crtsh").Infof("%s - queued %d subdomains", domain, queued)
+	}
+}
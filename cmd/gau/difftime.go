@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/difftime"
+	"github.com/lc/gau/v2/pkg/output"
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/lc/gau/v2/runner"
+	"github.com/lc/gau/v2/runner/flags"
+	log "github.com/sirupsen/logrus"
+)
+
+// dateProviders are the providers that honour the from/to filters,
+// the remaining providers can't be split into time windows.
+var dateProviders = map[string]bool{
+	"wayback":     true,
+	"commoncrawl": true,
+	"urlscan":     true,
+}
+
+// diffTime fetches the urls for domains in the window ending at --before and
+// in the window starting at --after, and writes the urls (or path templates)
+// that only appear in the earlier window. With --reverse the urls only
+// present in the later window are written instead.
+func diffTime(cfg *flags.Config, config *providers.Config, domains []string) error {
+	opts := cfg.DiffTime
+	if opts.Before == "" || opts.After == "" {
+		return errors.New("diff-time requires both --before and --after")
+	}
+	// the from/to filters take months, so years are widened to cover the whole year
+	before, err := windowMonth(opts.Before, true)
+	if err != nil {
+		return err
+	}
+	after, err := windowMonth(opts.After, false)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, name := range cfg.Providers {
+		if !dateProviders[name] {
+			log.Warnf("diff-time: skipping %s, it does not support date filters", name)
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return errors.New("diff-time: no providers with date filter support selected")
+	}
+
+	if len(domains) == 0 {
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			domains = append(domains, sc.Text())
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+	}
+
+	earlier := cfg.Filters
+	earlier.From, earlier.To = "", before
+
+	later := cfg.Filters
+	later.From, later.To = after, ""
+
+	earlierWindow, err := fetchWindow(config, names, earlier, domains, opts.Templates)
+	if err != nil {
+		return err
+	}
+	laterWindow, err := fetchWindow(config, names, later, domains, opts.Templates)
+	if err != nil {
+		return err
+	}
+	log.Infof("diff-time: %d entries up to %s, %d entries from %s", earlierWindow.Len(), before, laterWindow.Len(), after)
+
+	only := earlierWindow.Only(laterWindow)
+	if opts.Reverse {
+		only = laterWindow.Only(earlierWindow)
+	}
+
+	var out io.Writer = os.Stdout
+	if config.Output != "" {
+		f, err := os.OpenFile(config.Output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("could not open output file: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := bufio.NewWriter(out)
+	for _, entry := range only {
+		if _, err := w.WriteString(entry + "\n"); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// fetchWindow runs the given providers against domains using filters and
+// collects the normalized results.
+func fetchWindow(config *providers.Config, names []string, filters providers.Filters, domains []string, templates bool) (*difftime.Window, error) {
+	gau := new(runner.Runner)
+	if err := gau.Init(config, names, filters); err != nil {
+		log.Warn(err)
+	}
+	if len(gau.Providers) == 0 {
+		return nil, errors.New("diff-time: no providers could be initialized")
+	}
+
+	window := difftime.NewWindow(templates)
+	results := make(chan providers.Result)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range results {
+			if isBlacklisted(result.URL, config) {
+				continue
+			}
+			window.Add(result.URL)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	workChan := make(chan runner.Work)
+	gau.Start(ctx, workChan, results)
+	for _, provider := range gau.Providers {
+		for _, domain := range domains {
+			workChan <- runner.NewWork(domain, provider)
+		}
+	}
+	close(workChan)
+
+	gau.Wait()
+	close(results)
+	<-done
+
+	return window, nil
+}
+
+func isBlacklisted(result string, config *providers.Config) bool {
+	u, err := url.Parse(result)
+	if err != nil {
+		return true
+	}
+	return output.Blacklisted(config.Blacklist, u)
+}
+
+// windowMonth converts a diff-time date to the YYYYMM format of the from/to filters.
+// A year stands for its last month when it ends a window, and its first month otherwise.
+func windowMonth(date string, end bool) (string, error) {
+	if _, err := time.Parse("200601", date); err == nil {
+		return date, nil
+	}
+	if _, err := time.Parse("2006", date); err == nil {
+		if end {
+			return date + "12", nil
+		}
+		return date + "01", nil
+	}
+	return "", fmt.Errorf("invalid diff-time date %q (format: YYYY or YYYYMM)", date)
+}
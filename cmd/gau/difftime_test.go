@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+func TestWindowMonth(t *testing.T) {
+	tests := []struct {
+		date    string
+		end     bool
+		want    string
+		wantErr bool
+	}{
+		{date: "2019", end: true, want: "201912"},
+		{date: "2023", end: false, want: "202301"},
+		{date: "201906", end: true, want: "201906"},
+		{date: "202302", end: false, want: "202302"},
+		{date: "20190101", wantErr: true},
+		{date: "201913", wantErr: true},
+		{date: "19", wantErr: true},
+		{date: "last year", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := windowMonth(tt.date, tt.end)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("windowMonth(%q, %v) = %q, %v, want %q, wantErr %v", tt.date, tt.end, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestWindowMonthBoundsDateRange checks that year dates bound the windows of the
+// providers reading the from/to filters, such as urlscan
+func TestWindowMonthBoundsDateRange(t *testing.T) {
+	before, err := windowMonth("2019", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := windowMonth("2023", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	epoch := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	earlier := providers.Filters{To: before}
+	if w := earlier.DateRange(epoch); !w.To.Equal(time.Date(2019, time.December, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("earlier window = %s, want it to end on 2019-12-31", w)
+	}
+	later := providers.Filters{From: after}
+	if w := later.DateRange(epoch); !w.From.Equal(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("later window = %s, want it to start on 2023-01-01", w)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"os"
 	"sync"
 
+	"github.com/lc/gau/v2/pkg/classify"
 	"github.com/lc/gau/v2/pkg/output"
+	"github.com/lc/gau/v2/pkg/patterns"
+	"github.com/lc/gau/v2/pkg/providers"
 	"github.com/lc/gau/v2/runner"
 	"github.com/lc/gau/v2/runner/flags"
 	log "github.com/sirupsen/logrus"
@@ -24,13 +27,48 @@ func main() {
 		log.Fatal(err)
 	}
 
+	args := flags.Args()
+	if len(args) > 0 && args[0] == "diff-time" {
+		if err = diffTime(cfg, config, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	gau := new(runner.Runner)
 
 	if err = gau.Init(config, cfg.Providers, cfg.Filters); err != nil {
 		log.Warn(err)
 	}
 
-	results := make(chan string)
+	results := make(chan providers.Result)
+	// results go through the classifier and pattern stages before being written when enabled
+	written := results
+	if config.Classify.Enabled {
+		classifier, err := classify.New(config.Classify)
+		if err != nil {
+			log.Fatal(err)
+		}
+		written = make(chan providers.Result)
+		go func(classified chan<- providers.Result) {
+			if err := classifier.Run(results, classified, config.Classify.Dir, config.Blacklist); err != nil {
+				log.WithField("stage", "classify").Warn(err)
+			}
+		}(written)
+	}
+	if len(config.Patterns.Match) > 0 {
+		packs, err := patterns.Load(config.Patterns.Dirs)
+		if err != nil {
+			log.Fatal(err)
+		}
+		engine, err := patterns.New(packs, config.Patterns.Match, config.Patterns.Only)
+		if err != nil {
+			log.Fatal(err)
+		}
+		in := written
+		written = make(chan providers.Result)
+		go engine.Run(in, written)
+	}
 
 	out := os.Stdout
 	// Handle results in background
@@ -44,19 +82,17 @@ func main() {
 
 	var writeWg sync.WaitGroup
 	writeWg.Add(1)
-	go func(out io.Writer, JSON bool) {
+	go func(out io.Writer) {
 		defer writeWg.Done()
-		if JSON {
-			output.WriteURLsJSON(out, results, config.Blacklist, config.RemoveParameters)
-		} else if err = output.WriteURLs(out, results, config.Blacklist, config.RemoveParameters); err != nil {
+		if err := write(out, written, config); err != nil {
 			log.Fatalf("error writing results: %v\n", err)
 		}
-	}(out, config.JSON)
+	}(out)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	workChan := make(chan runner.Work)
 	gau.Start(ctx, workChan, results)
-	domains := flags.Args()
+	domains := args
 	if len(domains) > 0 {
 		for _, provider := range gau.Providers {
 			for _, domain := range domains {
@@ -67,6 +103,7 @@ func main() {
 		sc := bufio.NewScanner(os.Stdin)
 		for sc.Scan() {
 			domain := sc.Text()
+			domains = append(domains, domain)
 			for _, provider := range gau.Providers {
 				workChan <- runner.NewWork(domain, provider)
 			}
@@ -75,6 +112,9 @@ func main() {
 			log.Fatal(err)
 		}
 	}
+	if config.CrtSh.Enabled {
+		expandSubdomains(config, domains, gau.Providers, workChan)
+	}
 	close(workChan)
 
 	// wait for providers to fetch URLS
@@ -86,3 +126,37 @@ func main() {
 	// wait for writer to finish output
 	writeWg.Wait()
 }
+
+// write writes the results in the configured output mode
+func write(out io.Writer, results <-chan providers.Result, config *providers.Config) error {
+	switch config.OutputMode {
+	case output.ModeHosts:
+		if config.JSON {
+			return output.WriteHostsJSON(out, results, config.Blacklist, config.Origins)
+		}
+		if config.HostCounts {
+			return output.WriteHostCounts(out, results, config.Blacklist, config.Origins)
+		}
+		return output.WriteHosts(out, results, config.Blacklist, config.Origins)
+	case output.ModeParams:
+		opts := output.ParamOptions{Scope: config.ParamsScope, Sources: config.ParamsSources}
+		return output.WriteParams(out, results, config.Blacklist, opts, config.JSON)
+	case output.ModeValues:
+		opts := output.ValueOptions{Sources: config.ParamsSources, Max: config.ValuesMax, Dir: config.ValuesDir}
+		return output.WriteValues(out, results, config.Blacklist, opts, config.JSON)
+	case output.ModePaths:
+		opts := output.PathOptions{
+			Types:    config.PathTypes,
+			MinCount: config.PathsMinCount,
+			MaxDepth: config.PathsMaxDepth,
+			Merge:    config.PathsMerge,
+		}
+		return output.WritePaths(out, results, config.Blacklist, opts, config.JSON)
+	}
+
+	if config.JSON {
+		output.WriteURLsJSON(out, results, config.Blacklist, config.RemoveParameters)
+		return nil
+	}
+	return output.WriteURLs(out, results, config.Blacklist, config.RemoveParameters)
+}
@@ -0,0 +1,146 @@
+// Package classify tags results with categories such as js, api or backup, using
+// glob patterns on the extension, path segments, parameter names and mime type of a url.
+package classify
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lc/gau/v2/pkg/output"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// validName matches the category names, which are also used as file names
+var validName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+type category struct {
+	name string
+	providers.Category
+}
+
+type Classifier struct {
+	categories []category
+	match      map[string]struct{}
+}
+
+// New returns a classifier with the built-in categories extended by the configured rules.
+// Patterns are lowercased and checked, and every category to match must be known.
+func New(c providers.Classify) (*Classifier, error) {
+	rules := make(map[string]providers.Category, len(Builtin)+len(c.Rules))
+	for name, rule := range Builtin {
+		rules[name] = merge(providers.Category{}, rule)
+	}
+	for name, rule := range c.Rules {
+		if !validName.MatchString(name) {
+			return nil, fmt.Errorf("invalid category name: %s", name)
+		}
+		rules[name] = merge(rules[name], rule)
+	}
+
+	cl := &Classifier{match: make(map[string]struct{}, len(c.Match))}
+	for _, name := range sortedNames(rules) {
+		rule := rules[name]
+		for i, ext := range rule.Extensions {
+			rule.Extensions[i] = strings.TrimPrefix(ext, ".")
+		}
+		for _, patterns := range [][]string{rule.Extensions, rule.Paths, rule.MimeTypes, rule.Params} {
+			for i, pattern := range patterns {
+				patterns[i] = strings.ToLower(pattern)
+				if _, err := path.Match(patterns[i], ""); err != nil {
+					return nil, fmt.Errorf("category %s: invalid pattern %q", name, pattern)
+				}
+			}
+		}
+		cl.categories = append(cl.categories, category{name: name, Category: rule})
+	}
+
+	for _, name := range c.Match {
+		if _, ok := rules[name]; !ok {
+			return nil, fmt.Errorf("unknown category: %s", name)
+		}
+		cl.match[name] = struct{}{}
+	}
+	return cl, nil
+}
+
+// Categories returns the sorted categories of a result
+func (cl *Classifier) Categories(r providers.Result) []string {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(u.Path), "."))
+	var segments []string
+	for _, segment := range strings.Split(strings.ToLower(u.Path), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	var params []string
+	for _, pair := range output.QueryPairs(u.RawQuery) {
+		params = append(params, strings.ToLower(pair[0]))
+	}
+	mimeType, _, _ := strings.Cut(r.MimeType, ";")
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+
+	var categories []string
+	for _, c := range cl.categories {
+		if (ext != "" && matchAny(c.Extensions, ext)) ||
+			matchAny(c.Paths, segments...) ||
+			matchAny(c.Params, params...) ||
+			(mimeType != "" && matchAny(c.MimeTypes, mimeType)) {
+			categories = append(categories, c.name)
+		}
+	}
+	return categories
+}
+
+// Matches reports whether the categories include one of the categories to match.
+// Every result matches when none are configured.
+func (cl *Classifier) Matches(categories []string) bool {
+	if len(cl.match) == 0 {
+		return true
+	}
+	for _, c := range categories {
+		if _, ok := cl.match[c]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether one of the patterns matches one of the values
+func matchAny(patterns []string, values ...string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if ok, _ := path.Match(pattern, value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// merge returns a copy of a with the patterns of b appended
+func merge(a, b providers.Category) providers.Category {
+	return providers.Category{
+		Extensions: append(append([]string{}, a.Extensions...), b.Extensions...),
+		Paths:      append(append([]string{}, a.Paths...), b.Paths...),
+		MimeTypes:  append(append([]string{}, a.MimeTypes...), b.MimeTypes...),
+		Params:     append(append([]string{}, a.Params...), b.Params...),
+	}
+}
+
+func sortedNames(rules map[string]providers.Category) []string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
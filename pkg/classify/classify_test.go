@@ -0,0 +1,95 @@
+package classify
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+func TestCategories(t *testing.T) {
+	cl, err := New(providers.Classify{
+		Rules: map[string]providers.Category{
+			"graphql": {Paths: []string{"graphql"}, Params: []string{"operationName"}},
+			"js":      {Extensions: []string{".TS"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url      string
+		mimeType string
+		want     []string
+	}{
+		{url: "https://example.com/", want: nil},
+		{url: "https://example.com/static/app.JS", want: []string{"js"}},
+		{url: "https://example.com/src/main.ts", want: []string{"js"}},
+		{url: "https://example.com/app.js.map", want: []string{"sourcemap"}},
+		{url: "https://example.com/API/v2/users", want: []string{"api"}},
+		{url: "https://example.com/graphql?operationName=q", want: []string{"api", "graphql"}},
+		{url: "https://example.com/q?a=1;OperationName=q", want: []string{"graphql"}},
+		{url: "https://example.com/cb?redirect_uri=x&state=1", want: []string{"auth"}},
+		{url: "https://example.com/wp-admin/login.php", want: []string{"admin", "auth"}},
+		{url: "https://example.com/db.sql.gz", want: []string{"backup"}},
+		{url: "https://example.com/index.php~", want: []string{"backup"}},
+		{url: "https://example.com/download", mimeType: "Application/PDF; qs=1", want: []string{"document"}},
+		{url: "https://example.com/logo", mimeType: "image/png", want: []string{"static"}},
+		{url: "%zz", want: nil},
+	}
+	for _, tt := range tests {
+		got := cl.Categories(providers.Result{URL: tt.url, MimeType: tt.mimeType})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Categories(%q, %q) = %v, want %v", tt.url, tt.mimeType, got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  providers.Classify
+		wantErr bool
+	}{
+		{name: "builtin", config: providers.Classify{Match: []string{"js", "api"}}},
+		{name: "custom match", config: providers.Classify{Match: []string{"x"}, Rules: map[string]providers.Category{"x": {Paths: []string{"x"}}}}},
+		{name: "unknown category", config: providers.Classify{Match: []string{"nope"}}, wantErr: true},
+		{name: "invalid name", config: providers.Classify{Rules: map[string]providers.Category{"a/b": {}}}, wantErr: true},
+		{name: "invalid pattern", config: providers.Classify{Rules: map[string]providers.Category{"x": {Paths: []string{"["}}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	all, err := New(providers.Classify{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	some, err := New(providers.Classify{Match: []string{"js", "api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cl         *Classifier
+		categories []string
+		want       bool
+	}{
+		{cl: all, categories: nil, want: true},
+		{cl: some, categories: nil, want: false},
+		{cl: some, categories: []string{"static", "api"}, want: true},
+		{cl: some, categories: []string{"static"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.cl.Matches(tt.categories); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.categories, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package classify
+
+import "github.com/lc/gau/v2/pkg/providers"
+
+// Builtin holds the categories known without configuration. Rules from the config
+// file with the same name add their patterns to these.
+var Builtin = map[string]providers.Category{
+	"js": {
+		Extensions: []string{"js", "mjs", "jsx"},
+		MimeTypes:  []string{"application/javascript", "application/x-javascript", "text/javascript"},
+	},
+	"sourcemap": {
+		Extensions: []string{"map"},
+	},
+	"api": {
+		Extensions: []string{"json", "wsdl", "wadl"},
+		Paths:      []string{"api", "apis", "rest", "graphql", "graphiql", "v[0-9]", "v[0-9][0-9]", "swagger*", "openapi*", "api-docs"},
+		MimeTypes:  []string{"application/json", "application/*+json", "application/graphql"},
+	},
+	"document": {
+		Extensions: []string{"pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "odt", "ods", "odp", "rtf", "csv"},
+		MimeTypes:  []string{"application/pdf", "application/msword", "application/vnd.ms-*", "application/vnd.openxmlformats-officedocument.*", "application/vnd.oasis.opendocument.*", "text/csv"},
+	},
+	"admin": {
+		Paths: []string{"admin*", "wp-admin", "dashboard", "manage", "manager", "management", "cpanel", "console", "phpmyadmin", "backend", "cms"},
+	},
+	"auth": {
+		Paths:  []string{"login*", "logout*", "signin", "sign-in", "signup", "sign-up", "register", "auth*", "oauth*", "sso", "saml*", "cas", "password*", "forgot*", "reset*"},
+		Params: []string{"redirect_uri", "client_id", "response_type", "access_token", "id_token", "refresh_token", "samlrequest", "samlresponse"},
+	},
+	"static": {
+		Extensions: []string{"css", "png", "jpg", "jpeg", "gif", "svg", "ico", "webp", "bmp", "woff", "woff2", "ttf", "eot", "otf", "mp3", "mp4", "webm", "ogg"},
+		MimeTypes:  []string{"text/css", "image/*", "font/*", "audio/*", "video/*"},
+	},
+	"backup": {
+		Extensions: []string{"bak", "backup", "old", "orig", "save", "swp", "tmp", "sql", "dump", "zip", "tar", "gz", "tgz", "rar", "7z"},
+		Paths:      []string{"*~", "*.bak.*", "*.old.*"},
+	},
+}
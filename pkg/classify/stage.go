@@ -0,0 +1,91 @@
+package classify
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/lc/gau/v2/pkg/output"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// categoryFiles holds the files urls are written to, one per category, along with the
+// urls already written to each
+type categoryFiles struct {
+	dir   string
+	files map[string]*os.File
+	seen  map[string]map[string]struct{}
+}
+
+// Run tags the results read from in with their categories and passes on the ones
+// that match. When dir is set, the urls of every category that matches are also written
+// to <dir>/<category>.txt, each once. The files are truncated when first written to, and
+// urls with a blacklisted extension are left out as they are from the output.
+// out is closed once in is drained.
+func (cl *Classifier) Run(in <-chan providers.Result, out chan<- providers.Result, dir string, blacklist mapset.Set[string]) error {
+	defer close(out)
+
+	cf := &categoryFiles{
+		dir:   dir,
+		files: make(map[string]*os.File),
+		seen:  make(map[string]map[string]struct{}),
+	}
+	defer func() {
+		for _, f := range cf.files {
+			f.Close()
+		}
+	}()
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			// keep draining so the providers are not blocked
+			for range in {
+			}
+			return err
+		}
+	}
+
+	var err error
+	for result := range in {
+		result.Categories = cl.Categories(result)
+		if !cl.Matches(result.Categories) {
+			continue
+		}
+		if dir != "" && err == nil {
+			if u, perr := url.Parse(result.URL); perr == nil && !output.Blacklisted(blacklist, u) {
+				err = cl.writeURL(cf, result)
+			}
+		}
+		out <- result
+	}
+	return err
+}
+
+// writeURL writes the url of a result to the file of each of its categories that match,
+// unless it was written to it before
+func (cl *Classifier) writeURL(cf *categoryFiles, result providers.Result) error {
+	for _, c := range result.Categories {
+		if !cl.Matches([]string{c}) {
+			continue
+		}
+		f, ok := cf.files[c]
+		if !ok {
+			var err error
+			f, err = os.OpenFile(filepath.Join(cf.dir, c+".txt"), os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+			if err != nil {
+				return err
+			}
+			cf.files[c] = f
+			cf.seen[c] = make(map[string]struct{})
+		}
+		if _, ok := cf.seen[c][result.URL]; ok {
+			continue
+		}
+		cf.seen[c][result.URL] = struct{}{}
+		if _, err := f.WriteString(result.URL + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package crtsh discovers subdomains from certificate transparency logs
+// using the json output of crt.sh or a compatible endpoint.
+package crtsh
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/httpclient"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// DefaultBaseURL is the location of crt.sh
+const DefaultBaseURL = "https://crt.sh/"
+
+type Client struct {
+	config  *providers.Config
+	baseURL string
+}
+
+type certificate struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"`
+}
+
+func New(c *providers.Config) *Client {
+	return &Client{
+		config:  c,
+		baseURL: providers.BaseURL(c.CrtSh.Host, DefaultBaseURL),
+	}
+}
+
+// Subdomains returns the sorted, unique subdomains of domain named in certificates.
+// Wildcards are reduced to their base host, and names outside of domain are dropped.
+func (c *Client) Subdomains(domain string) ([]string, error) {
+	apiURL := fmt.Sprintf("%s?q=%s&output=json", c.baseURL, url.QueryEscape("%."+domain))
+	resp, err := httpclient.MakeRequest(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch crt.sh: %s", err)
+	}
+
+	var certs []certificate
+	if err = jsoniter.Unmarshal(resp, &certs); err != nil {
+		return nil, fmt.Errorf("failed to decode crt.sh result: %s", err)
+	}
+
+	seen := make(map[string]struct{})
+	for _, cert := range certs {
+		// name_value holds every name of the certificate, one per line
+		for _, name := range strings.Split(cert.NameValue+"\n"+cert.CommonName, "\n") {
+			host, ok := normalize(name, domain)
+			if ok {
+				seen[host] = struct{}{}
+			}
+		}
+	}
+
+	hosts := make([]string, 0, len(seen))
+	for host := range seen {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// normalize cleans a certificate name and reports whether it is a subdomain of domain
+func normalize(name, domain string) (string, bool) {
+	host := strings.ToLower(strings.TrimSpace(name))
+	host = strings.TrimPrefix(host, "*.")
+	host = strings.TrimSuffix(host, ".")
+	if host == domain || !strings.HasSuffix(host, "."+domain) {
+		return "", false
+	}
+	for _, r := range host {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.') {
+			return "", false
+		}
+	}
+	return host, true
+}
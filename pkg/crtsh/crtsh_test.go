@@ -0,0 +1,27 @@
+package crtsh
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{name: "www.example.com", want: "www.example.com", ok: true},
+		{name: " *.API.Example.com. ", want: "api.example.com", ok: true},
+		{name: "a.b-c.example.com", want: "a.b-c.example.com", ok: true},
+		{name: "example.com", ok: false},
+		{name: "*.example.com", ok: false},
+		{name: "notexample.com", ok: false},
+		{name: "example.com.evil.org", ok: false},
+		{name: "foo_bar.example.com", ok: false},
+		{name: "a.*.example.com", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := normalize(tt.name, "example.com")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalize(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
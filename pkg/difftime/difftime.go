@@ -0,0 +1,132 @@
+package difftime
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	intSegment  = regexp.MustCompile(`^[0-9]+$`)
+	uuidSegment = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexSegment  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+)
+
+// Window holds the normalized urls seen within a single time window,
+// mapped to the first raw url that produced them.
+type Window struct {
+	templates bool
+	seen      map[string]string
+}
+
+// NewWindow returns an empty Window. If templates is true, urls are reduced
+// to path templates before being compared.
+func NewWindow(templates bool) *Window {
+	return &Window{templates: templates, seen: make(map[string]string)}
+}
+
+// Add normalizes rawURL and records it in the window.
+func (w *Window) Add(rawURL string) {
+	key, ok := Normalize(rawURL, w.templates)
+	if !ok {
+		return
+	}
+	if _, exists := w.seen[key]; !exists {
+		w.seen[key] = rawURL
+	}
+}
+
+// Len returns the number of unique entries in the window.
+func (w *Window) Len() int {
+	return len(w.seen)
+}
+
+// Only returns the entries of w that are not present in other, sorted.
+// Path templates are returned as-is, full urls are returned as they were
+// first seen.
+func (w *Window) Only(other *Window) []string {
+	var out []string
+	for key, raw := range w.seen {
+		if _, ok := other.seen[key]; ok {
+			continue
+		}
+		if w.templates {
+			out = append(out, key)
+		} else {
+			out = append(out, raw)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Normalize returns a comparison key for rawURL. The scheme, default ports,
+// fragment and trailing slashes are dropped, the host is lowercased and
+// query parameters are sorted. If templates is true, variable looking path
+// segments are replaced with placeholders and query values are discarded.
+func Normalize(rawURL string, templates bool) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	p := strings.TrimRight(u.EscapedPath(), "/")
+	if templates {
+		p = Template(p)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(host)
+	sb.WriteString(p)
+
+	query := u.Query()
+	if len(query) == 0 {
+		return sb.String(), true
+	}
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sb.WriteByte('?')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(url.QueryEscape(k))
+		if templates {
+			continue
+		}
+		values := query[k]
+		sort.Strings(values)
+		sb.WriteByte('=')
+		sb.WriteString(url.QueryEscape(strings.Join(values, ",")))
+	}
+	return sb.String(), true
+}
+
+// Template replaces numeric, uuid and long hex segments of an escaped path
+// with {int}, {uuid} and {hex} placeholders respectively.
+func Template(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		switch {
+		case intSegment.MatchString(s):
+			segments[i] = "{int}"
+		case uuidSegment.MatchString(s):
+			segments[i] = "{uuid}"
+		case hexSegment.MatchString(s):
+			segments[i] = "{hex}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
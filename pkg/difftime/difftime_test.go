@@ -0,0 +1,94 @@
+package difftime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		url       string
+		templates bool
+		want      string
+		ok        bool
+	}{
+		{url: "https://Example.com/a/", want: "example.com/a", ok: true},
+		{url: "http://example.com:80/a#frag", want: "example.com/a", ok: true},
+		{url: "https://example.com:443/", want: "example.com", ok: true},
+		{url: "https://example.com:8443/a", want: "example.com:8443/a", ok: true},
+		{url: "https://example.com/a?b=2&a=1&a=0", want: "example.com/a?a=0%2C1&b=2", ok: true},
+		{url: " https://example.com/a%20b ", want: "example.com/a%20b", ok: true},
+		{url: "https://example.com/users/42/orders?id=7&sort=asc", templates: true, want: "example.com/users/{int}/orders?id&sort", ok: true},
+		{url: "/relative/path", ok: false},
+		{url: "%zz", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := Normalize(tt.url, tt.templates)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q, %v) = %q, %v, want %q, %v", tt.url, tt.templates, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/v2/users", want: "/api/v2/users"},
+		{path: "/users/42", want: "/users/{int}"},
+		{path: "/orders/123e4567-E89B-12d3-a456-426614174000/items/7", want: "/orders/{uuid}/items/{int}"},
+		{path: "/blob/0123456789abcdef0123", want: "/blob/{hex}"},
+		// short hex strings are left alone, they are often words
+		{path: "/cafe/deadbeef", want: "/cafe/deadbeef"},
+	}
+	for _, tt := range tests {
+		if got := Template(tt.path); got != tt.want {
+			t.Errorf("Template(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWindowOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates bool
+		earlier   []string
+		later     []string
+		want      []string
+		reverse   []string
+	}{
+		{
+			name:    "urls",
+			earlier: []string{"https://example.com/old?b=1&a=2", "http://example.com/kept/", "https://example.com/gone"},
+			later:   []string{"https://example.com/kept", "https://example.com/old?a=2&b=1", "https://example.com/new"},
+			want:    []string{"https://example.com/gone"},
+			reverse: []string{"https://example.com/new"},
+		},
+		{
+			name:      "templates",
+			templates: true,
+			earlier:   []string{"https://example.com/users/1?tab=a", "https://example.com/legacy/2"},
+			later:     []string{"https://example.com/users/99?tab=b"},
+			want:      []string{"example.com/legacy/{int}"},
+			reverse:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			earlier, later := NewWindow(tt.templates), NewWindow(tt.templates)
+			for _, u := range tt.earlier {
+				earlier.Add(u)
+			}
+			for _, u := range tt.later {
+				later.Add(u)
+			}
+			if got := earlier.Only(later); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Only() = %v, want %v", got, tt.want)
+			}
+			if got := later.Only(earlier); !reflect.DeepEqual(got, tt.reverse) {
+				t.Errorf("reverse Only() = %v, want %v", got, tt.reverse)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package httpclient
 import (
 	"errors"
 	"math/rand"
+	"net/http"
+	neturl "net/url"
 	"time"
 
 	"github.com/valyala/fasthttp"
@@ -12,6 +14,7 @@ var (
 	ErrNilResponse    = errors.New("unexpected nil response")
 	ErrNon200Response = errors.New("API responded with non-200 status code")
 	ErrBadRequest     = errors.New("API responded with 400 status code")
+	ErrRateLimited    = errors.New("API responded with 429 status code")
 )
 
 type Header struct {
@@ -19,26 +22,60 @@ type Header struct {
 	Value string
 }
 
+// Response holds the parts of an HTTP response providers inspect
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
 func MakeRequest(c *fasthttp.Client, url string, maxRetries uint, timeout uint, headers ...Header) ([]byte, error) {
 	var (
-		req      *fasthttp.Request
-		respBody []byte
-		err      error
+		resp *Response
+		err  error
 	)
 	retries := int(maxRetries)
 	for i := retries; i >= 0; i-- {
-		req = fasthttp.AcquireRequest()
+		resp, err = doReq(c, newRequest(fasthttp.MethodGet, url, nil, headers), timeout)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
 
-		req.Header.SetMethod(fasthttp.MethodGet)
-		for _, header := range headers {
-			if header.Key != "" {
-				req.Header.Set(header.Key, header.Value)
-			}
+// Do makes a request like MakeRequest but returns the whole response. Rate limited
+// requests are not retried, the response is returned along with ErrRateLimited
+// so that callers can inspect the rate limit headers.
+func Do(c *fasthttp.Client, url string, maxRetries uint, timeout uint, headers ...Header) (*Response, error) {
+	var (
+		resp *Response
+		err  error
+	)
+	retries := int(maxRetries)
+	for i := retries; i >= 0; i-- {
+		resp, err = doReq(c, newRequest(fasthttp.MethodGet, url, nil, headers), timeout)
+		if err == nil || errors.Is(err, ErrRateLimited) {
+			break
 		}
-		req.Header.Set(fasthttp.HeaderUserAgent, getUserAgent())
-		req.Header.Set("Accept", "*/*")
-		req.SetRequestURI(url)
-		respBody, err = doReq(c, req, timeout)
+	}
+	return resp, err
+}
+
+// PostForm makes a POST request with an url-encoded form body, retrying like MakeRequest
+func PostForm(c *fasthttp.Client, url string, form neturl.Values, maxRetries uint, timeout uint, headers ...Header) ([]byte, error) {
+	var (
+		resp *Response
+		err  error
+	)
+	retries := int(maxRetries)
+	for i := retries; i >= 0; i-- {
+		req := newRequest(fasthttp.MethodPost, url, []byte(form.Encode()), headers)
+		req.Header.SetContentType("application/x-www-form-urlencoded")
+		resp, err = doReq(c, req, timeout)
 		if err == nil {
 			break
 		}
@@ -46,28 +83,59 @@ func MakeRequest(c *fasthttp.Client, url string, maxRetries uint, timeout uint,
 	if err != nil {
 		return nil, err
 	}
-	return respBody, nil
+	return resp.Body, nil
+}
+
+func newRequest(method, url string, body []byte, headers []Header) *fasthttp.Request {
+	req := fasthttp.AcquireRequest()
+
+	req.Header.SetMethod(method)
+	if body != nil {
+		req.SetBody(body)
+	}
+	for _, header := range headers {
+		if header.Key != "" {
+			req.Header.Set(header.Key, header.Value)
+		}
+	}
+	req.Header.Set(fasthttp.HeaderUserAgent, getUserAgent())
+	req.Header.Set("Accept", "*/*")
+	req.SetRequestURI(url)
+	return req
 }
 
 // doReq handles http requests
-func doReq(c *fasthttp.Client, req *fasthttp.Request, timeout uint) ([]byte, error) {
+func doReq(c *fasthttp.Client, req *fasthttp.Request, timeout uint) (*Response, error) {
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 	defer fasthttp.ReleaseRequest(req)
 	if err := c.DoTimeout(req, resp, time.Second*time.Duration(timeout)); err != nil {
 		return nil, err
 	}
+
+	r := &Response{
+		StatusCode: resp.StatusCode(),
+		Header:     make(http.Header),
+		Body:       append([]byte(nil), resp.Body()...),
+	}
+	resp.Header.VisitAll(func(key, value []byte) {
+		r.Header.Add(string(key), string(value))
+	})
+
 	if resp.StatusCode() != 200 {
-		if resp.StatusCode() == 400 {
-			return nil, ErrBadRequest
+		switch resp.StatusCode() {
+		case 400:
+			return r, ErrBadRequest
+		case 429:
+			return r, ErrRateLimited
 		}
-		return nil, ErrNon200Response
+		return r, ErrNon200Response
 	}
 	if resp.Body() == nil {
 		return nil, ErrNilResponse
 	}
 
-	return resp.Body(), nil
+	return r, nil
 }
 
 func getUserAgent() string {
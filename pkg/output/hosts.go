@@ -0,0 +1,137 @@
+package output
+
+import (
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// HostResult summarizes the urls seen on a host
+type HostResult struct {
+	Host      string   `json:"host"`
+	Count     int      `json:"count"`
+	FirstSeen string   `json:"first_seen,omitempty"`
+	LastSeen  string   `json:"last_seen,omitempty"`
+	Sources   []string `json:"sources"`
+
+	first, last time.Time
+	sources     map[string]struct{}
+}
+
+// hostKey returns the hostname of a url, or its scheme, host and port if origins is set.
+// Urls without a host or with a blacklisted extension are skipped.
+func hostKey(rawURL string, blacklist mapset.Set[string], origins bool) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || Blacklisted(blacklist, u) {
+		return "", false
+	}
+	host := strings.ToLower(u.Hostname())
+	if !origins {
+		return host, true
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "" {
+		scheme = "http"
+	}
+	port := u.Port()
+	if port == "" || (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		return scheme + "://" + host, true
+	}
+	return scheme + "://" + host + ":" + port, true
+}
+
+// WriteHosts writes every host the first time it is seen, so only the set of hosts is kept in memory.
+// With origins, scheme://host:port origins are written instead of hostnames.
+func WriteHosts(writer io.Writer, results <-chan providers.Result, blacklist mapset.Set[string], origins bool) error {
+	seen := make(map[string]struct{})
+	for result := range results {
+		host, ok := hostKey(result.URL, blacklist, origins)
+		if !ok {
+			continue
+		}
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		if _, err := io.WriteString(writer, host+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteHostsJSON writes a summary of every host once all results were received: the number
+// of urls seen on it, the first and last time they were seen, and the sources reporting them.
+// Hosts are written in the order they were first seen.
+func WriteHostsJSON(writer io.Writer, results <-chan providers.Result, blacklist mapset.Set[string], origins bool) error {
+	enc := jsoniter.NewEncoder(writer)
+	for _, h := range summarizeHosts(results, blacklist, origins) {
+		if err := enc.Encode(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteHostCounts writes every host with the number of urls seen on it, separated by a tab,
+// once all results were received. Hosts are written in the order they were first seen.
+func WriteHostCounts(writer io.Writer, results <-chan providers.Result, blacklist mapset.Set[string], origins bool) error {
+	for _, h := range summarizeHosts(results, blacklist, origins) {
+		if _, err := io.WriteString(writer, h.Host+"\t"+strconv.Itoa(h.Count)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summarizeHosts reads all results and returns the summary of each host, in the order
+// the hosts were first seen
+func summarizeHosts(results <-chan providers.Result, blacklist mapset.Set[string], origins bool) []*HostResult {
+	var order []*HostResult
+	hosts := make(map[string]*HostResult)
+	for result := range results {
+		host, ok := hostKey(result.URL, blacklist, origins)
+		if !ok {
+			continue
+		}
+		h, ok := hosts[host]
+		if !ok {
+			h = &HostResult{Host: host, sources: make(map[string]struct{})}
+			hosts[host] = h
+			order = append(order, h)
+		}
+		h.Count++
+		if result.Source != "" {
+			h.sources[result.Source] = struct{}{}
+		}
+		if t, err := time.Parse(time.RFC3339, result.Timestamp); err == nil {
+			if h.first.IsZero() || t.Before(h.first) {
+				h.first = t
+			}
+			if t.After(h.last) {
+				h.last = t
+			}
+		}
+	}
+
+	for _, h := range order {
+		h.Sources = make([]string, 0, len(h.sources))
+		for source := range h.sources {
+			h.Sources = append(h.Sources, source)
+		}
+		sort.Strings(h.Sources)
+		if !h.first.IsZero() {
+			h.FirstSeen = h.first.UTC().Format(time.RFC3339)
+			h.LastSeen = h.last.UTC().Format(time.RFC3339)
+		}
+	}
+	return order
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+func TestHostKey(t *testing.T) {
+	blacklist := mapset.NewThreadUnsafeSet("", ".png")
+	tests := []struct {
+		url     string
+		origins bool
+		want    string
+		ok      bool
+	}{
+		{url: "https://WWW.Example.com/a", want: "www.example.com", ok: true},
+		{url: "https://example.com:443/a", origins: true, want: "https://example.com", ok: true},
+		{url: "http://example.com:8080/", origins: true, want: "http://example.com:8080", ok: true},
+		{url: "//example.com/a", origins: true, want: "http://example.com", ok: true},
+		{url: "https://example.com/logo.PNG", ok: false},
+		{url: "/relative", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := hostKey(tt.url, blacklist, tt.origins)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("hostKey(%q, %v) = %q, %v, want %q, %v", tt.url, tt.origins, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWriteHosts(t *testing.T) {
+	urls := []string{"https://b.example.com/1", "https://a.example.com/", "https://b.example.com/2", "https://c.example.com/x.png"}
+	blacklist := mapset.NewThreadUnsafeSet("", "png")
+
+	var buf bytes.Buffer
+	if err := WriteHosts(&buf, sendResults(urls...), blacklist, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "b.example.com\na.example.com\n"; buf.String() != want {
+		t.Errorf("WriteHosts() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteHostCounts(&buf, sendResults(urls...), blacklist, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "b.example.com\t2\na.example.com\t1\n"; buf.String() != want {
+		t.Errorf("WriteHostCounts() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteHostsJSON(t *testing.T) {
+	results := make(chan providers.Result, 3)
+	results <- providers.Result{URL: "https://example.com/a", Source: "wayback", Timestamp: "2020-01-01T00:00:00Z"}
+	results <- providers.Result{URL: "https://example.com/b", Source: "otx", Timestamp: "2019-06-01T00:00:00Z"}
+	results <- providers.Result{URL: "https://example.com/c", Source: "wayback"}
+	close(results)
+
+	var buf bytes.Buffer
+	if err := WriteHostsJSON(&buf, results, mapset.NewThreadUnsafeSet(""), false); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"host":"example.com","count":3,"first_seen":"2019-06-01T00:00:00Z","last_seen":"2020-01-01T00:00:00Z","sources":["otx","wayback"]}` + "\n"
+	if buf.String() != want {
+		t.Errorf("WriteHostsJSON() = %q, want %q", buf.String(), want)
+	}
+}
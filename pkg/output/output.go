@@ -8,22 +8,39 @@ import (
 
 	mapset "github.com/deckarep/golang-set/v2"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
 	"github.com/valyala/bytebufferpool"
 )
 
-type JSONResult struct {
-	Url string `json:"url"`
+const (
+	// ModeURLs writes every url
+	ModeURLs = "urls"
+	// ModeHosts writes the unique hosts of the urls
+	ModeHosts = "hosts"
+	// ModeParams writes the parameter names of the urls
+	ModeParams = "params"
+	// ModeValues writes the parameter values of the urls
+	ModeValues = "values"
+	// ModePaths writes the directories, file names and paths of the urls
+	ModePaths = "paths"
+)
+
+// Blacklisted reports whether the extension of the path of u is blacklisted. Extensions are
+// compared case-insensitively and blacklist entries match with or without their leading dot.
+func Blacklisted(blacklist mapset.Set[string], u *url.URL) bool {
+	ext := strings.ToLower(path.Ext(u.Path))
+	return ext != "" && (blacklist.Contains(ext) || blacklist.Contains(ext[1:]))
 }
 
-func WriteURLs(writer io.Writer, results <-chan string, blacklistMap mapset.Set[string], RemoveParameters bool) error {
+func WriteURLs(writer io.Writer, results <-chan providers.Result, blacklistMap mapset.Set[string], RemoveParameters bool) error {
 	lastURL := mapset.NewThreadUnsafeSet[string]()
 	for result := range results {
 		buf := bytebufferpool.Get()
-		u, err := url.Parse(result)
+		u, err := url.Parse(result.URL)
 		if err != nil {
 			continue
 		}
-		if path.Ext(u.Path) != "" && blacklistMap.Contains(strings.ToLower(path.Ext(u.Path))) {
+		if Blacklisted(blacklistMap, u) {
 			continue
 		}
 
@@ -32,7 +49,7 @@ func WriteURLs(writer io.Writer, results <-chan string, blacklistMap mapset.Set[
 		}
 		lastURL.Add(u.Host + u.Path)
 
-		buf.B = append(buf.B, []byte(result)...)
+		buf.B = append(buf.B, []byte(result.URL)...)
 		buf.B = append(buf.B, "\n"...)
 		_, err = writer.Write(buf.B)
 		if err != nil {
@@ -43,19 +60,17 @@ func WriteURLs(writer io.Writer, results <-chan string, blacklistMap mapset.Set[
 	return nil
 }
 
-func WriteURLsJSON(writer io.Writer, results <-chan string, blacklistMap mapset.Set[string], RemoveParameters bool) {
-	var jr JSONResult
+func WriteURLsJSON(writer io.Writer, results <-chan providers.Result, blacklistMap mapset.Set[string], RemoveParameters bool) {
 	enc := jsoniter.NewEncoder(writer)
 	for result := range results {
-		u, err := url.Parse(result)
+		u, err := url.Parse(result.URL)
 		if err != nil {
 			continue
 		}
-		if blacklistMap.Contains(strings.ToLower(path.Ext(u.Path))) {
+		if Blacklisted(blacklistMap, u) {
 			continue
 		}
-		jr.Url = result
-		if err := enc.Encode(jr); err != nil {
+		if err := enc.Encode(result); err != nil {
 			// todo: handle this error
 			continue
 		}
@@ -0,0 +1,216 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+const (
+	// ScopeGlobal counts parameter names across all hosts
+	ScopeGlobal = "global"
+	// ScopeHost counts parameter names separately for every host
+	ScopeHost = "host"
+
+	// ParamsQuery reads parameter names from the query string
+	ParamsQuery = "query"
+	// ParamsFragment reads parameter names from form-style fragments such as #a=1&b=2
+	ParamsFragment = "fragment"
+	// ParamsPath reads parameter names from matrix (;a=1), key=value and json path segments
+	ParamsPath = "path"
+
+	// maxParamPaths bounds the number of paths kept for every parameter name
+	maxParamPaths = 50
+)
+
+// jsonKey matches the keys of json objects embedded in a path
+var jsonKey = regexp.MustCompile(`"([A-Za-z0-9_.\-\[\]]+)"\s*:`)
+
+// ParamOptions configures the params output mode
+type ParamOptions struct {
+	Scope   string
+	Sources []string
+}
+
+// ParamResult holds the occurrences of a parameter name
+type ParamResult struct {
+	Host  string   `json:"host,omitempty"`
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Hosts []string `json:"hosts"`
+	Paths []string `json:"paths"`
+
+	hosts map[string]struct{}
+	paths map[string]struct{}
+}
+
+// ParamNames returns the parameter names of u found in the given sources, in order of appearance
+func ParamNames(u *url.URL, sources []string) []string {
+	var names []string
+	for _, source := range sources {
+		switch source {
+		case ParamsQuery:
+			names = append(names, queryNames(u.RawQuery)...)
+		case ParamsFragment:
+			fragment := u.EscapedFragment()
+			if i := strings.IndexByte(fragment, '?'); i >= 0 {
+				fragment = fragment[i+1:]
+			}
+			if strings.Contains(fragment, "=") {
+				names = append(names, queryNames(fragment)...)
+			}
+		case ParamsPath:
+			names = append(names, pathNames(u.EscapedPath())...)
+		}
+	}
+	return names
+}
+
+// QueryPairs returns the decoded name and value pairs of a form-encoded string split on & and ;,
+// tolerating malformed pairs. Pairs without a name are dropped.
+func QueryPairs(query string) [][2]string {
+	var pairs [][2]string
+	for _, pair := range strings.FieldsFunc(query, func(r rune) bool { return r == '&' || r == ';' }) {
+		name, value, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			pairs = append(pairs, [2]string{name, value})
+		}
+	}
+	return pairs
+}
+
+// queryNames returns the names of a form-encoded string
+func queryNames(query string) []string {
+	var names []string
+	for _, pair := range QueryPairs(query) {
+		names = append(names, pair[0])
+	}
+	return names
+}
+
+// pathNames returns the names of matrix parameters, key=value segments and json objects in a path
+func pathNames(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		if strings.Contains(unescaped, "{") {
+			for _, m := range jsonKey.FindAllStringSubmatch(unescaped, -1) {
+				names = append(names, m[1])
+			}
+			continue
+		}
+
+		if i := strings.IndexByte(unescaped, ';'); i >= 0 {
+			names = append(names, queryNames(unescaped[i+1:])...)
+			continue
+		}
+		if name, _, ok := strings.Cut(unescaped, "="); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// WriteParams collects the parameter names of all results and writes them once all results
+// were received, most frequent first. Names are written one per line with their count, separated
+// by a tab and prefixed with their host in host scope. With json, the hosts and paths of every
+// name are written too.
+func WriteParams(writer io.Writer, results <-chan providers.Result, blacklist mapset.Set[string], opts ParamOptions, JSON bool) error {
+	params := make(map[string]*ParamResult)
+	for result := range results {
+		u, err := url.Parse(result.URL)
+		if err != nil || Blacklisted(blacklist, u) {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+
+		// count every name once per url
+		names := make(map[string]struct{})
+		for _, name := range ParamNames(u, opts.Sources) {
+			if _, ok := names[name]; ok {
+				continue
+			}
+			names[name] = struct{}{}
+
+			key := name
+			if opts.Scope == ScopeHost {
+				key = host + "\x00" + name
+			}
+			p, ok := params[key]
+			if !ok {
+				p = &ParamResult{Name: name, hosts: make(map[string]struct{}), paths: make(map[string]struct{})}
+				if opts.Scope == ScopeHost {
+					p.Host = host
+				}
+				params[key] = p
+			}
+			p.Count++
+			p.hosts[host] = struct{}{}
+			if len(p.paths) < maxParamPaths {
+				p.paths[u.EscapedPath()] = struct{}{}
+			}
+		}
+	}
+
+	sorted := make([]*ParamResult, 0, len(params))
+	for _, p := range params {
+		sorted = append(sorted, p)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+
+	enc := jsoniter.NewEncoder(writer)
+	for _, p := range sorted {
+		if !JSON {
+			line := p.Name + "\t" + strconv.Itoa(p.Count) + "\n"
+			if p.Host != "" {
+				line = p.Host + "\t" + line
+			}
+			if _, err := io.WriteString(writer, line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		p.Hosts = sortedKeys(p.hosts)
+		p.Paths = sortedKeys(p.paths)
+		if err := enc.Encode(p); err != nil {
+			return fmt.Errorf("failed to encode %s: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
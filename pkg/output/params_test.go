@@ -0,0 +1,128 @@
+package output
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+func TestQueryPairs(t *testing.T) {
+	tests := []struct {
+		query string
+		want  [][2]string
+	}{
+		{query: "", want: nil},
+		{query: "a=1&b=2", want: [][2]string{{"a", "1"}, {"b", "2"}}},
+		{query: "a=1;b=2&&c", want: [][2]string{{"a", "1"}, {"b", "2"}, {"c", ""}}},
+		{query: "q=a+b&n%5B%5D=%2F", want: [][2]string{{"q", "a b"}, {"n[]", "/"}}},
+		{query: "bad=%zz&%zz=1", want: [][2]string{{"bad", "%zz"}, {"%zz", "1"}}},
+		{query: "=1&%20=2&x==", want: [][2]string{{"x", "="}}},
+	}
+	for _, tt := range tests {
+		if got := QueryPairs(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("QueryPairs(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryNames(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "a=1&b&c=", want: []string{"a", "b", "c"}},
+		{query: "jsessionid=1;x=2", want: []string{"jsessionid", "x"}},
+		{query: "&;=", want: nil},
+	}
+	for _, tt := range tests {
+		if got := queryNames(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("queryNames(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestPathNames(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "/a/b/c.php", want: nil},
+		{path: "/cart;jsessionid=1;lang=en/item", want: []string{"jsessionid", "lang"}},
+		{path: "/search/q=shoes/page=2", want: []string{"q", "page"}},
+		{path: `/api/%7B%22user%22:1,%22opts%22:%7B%22a.b%22:2%7D%7D`, want: []string{"user", "opts", "a.b"}},
+		{path: "/=x/%zz=1", want: []string{"%zz"}},
+	}
+	for _, tt := range tests {
+		if got := pathNames(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pathNames(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParamNames(t *testing.T) {
+	u, err := url.Parse("https://example.com/p;sid=1/x?a=1&b=2#/route?next=/home&c=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		sources []string
+		want    []string
+	}{
+		{sources: []string{ParamsQuery}, want: []string{"a", "b"}},
+		{sources: []string{ParamsFragment}, want: []string{"next", "c"}},
+		{sources: []string{ParamsPath}, want: []string{"sid"}},
+		{sources: []string{ParamsQuery, ParamsFragment, ParamsPath}, want: []string{"a", "b", "next", "c", "sid"}},
+	}
+	for _, tt := range tests {
+		if got := ParamNames(u, tt.sources); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParamNames(%v) = %q, want %q", tt.sources, got, tt.want)
+		}
+	}
+}
+
+func TestWriteParams(t *testing.T) {
+	urls := []string{
+		"https://example.com/a?id=1&id=2&q=x",
+		"https://www.example.com/b?id=3",
+		"https://example.com/img.png?id=4",
+		"https://example.com/c;sid=1?page=2",
+	}
+	blacklist := mapset.NewThreadUnsafeSet("", "png")
+	tests := []struct {
+		name string
+		opts ParamOptions
+		JSON bool
+		want string
+	}{
+		{
+			name: "global",
+			opts: ParamOptions{Sources: []string{ParamsQuery}},
+			want: "id\t2\npage\t1\nq\t1\n",
+		},
+		{
+			name: "host scope",
+			opts: ParamOptions{Scope: ScopeHost, Sources: []string{ParamsQuery, ParamsPath}},
+			want: "example.com\tid\t1\nexample.com\tpage\t1\nexample.com\tq\t1\nexample.com\tsid\t1\nwww.example.com\tid\t1\n",
+		},
+		{
+			name: "json",
+			opts: ParamOptions{Sources: []string{ParamsPath}},
+			JSON: true,
+			want: `{"name":"sid","count":1,"hosts":["example.com"],"paths":["/c;sid=1"]}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteParams(&buf, sendResults(urls...), blacklist, tt.opts, tt.JSON); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteParams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package output
+
+import (
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bobesa/go-domain-util/domainutil"
+	mapset "github.com/deckarep/golang-set/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+const (
+	// PathDirs are the directory segments of the paths
+	PathDirs = "dirs"
+	// PathFiles are the file names, with an extension, the paths end with
+	PathFiles = "files"
+	// PathFull are the full paths
+	PathFull = "paths"
+)
+
+// PathOptions configures the paths output mode
+type PathOptions struct {
+	Types    []string
+	MinCount uint
+	// MaxDepth is the deepest path segment counted, 0 means no limit
+	MaxDepth uint
+	// Merge ranks the entries of all domains in a single list
+	Merge bool
+}
+
+// PathResult holds the frequency of a path entry
+type PathResult struct {
+	Domain string `json:"domain,omitempty"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Count  int    `json:"count"`
+}
+
+type pathKey struct {
+	domain, typ, value string
+}
+
+// pathEntries returns the entries of an escaped path for the given types
+func pathEntries(p string, opts PathOptions) []pathKey {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	if len(segments) == 1 && segments[0] == "" {
+		return nil
+	}
+	depth := uint(len(segments))
+	// the last segment is a file if it has an extension
+	last := segments[len(segments)-1]
+	isFile := path.Ext(last) != "" && !strings.HasSuffix(p, "/")
+	dirs := segments
+	if isFile {
+		dirs = dirs[:len(dirs)-1]
+	}
+	if opts.MaxDepth > 0 && uint(len(dirs)) > opts.MaxDepth {
+		dirs = dirs[:opts.MaxDepth]
+	}
+	withinDepth := opts.MaxDepth == 0 || depth <= opts.MaxDepth
+
+	var entries []pathKey
+	for _, typ := range opts.Types {
+		switch typ {
+		case PathDirs:
+			for _, dir := range dirs {
+				entries = append(entries, pathKey{typ: PathDirs, value: dir})
+			}
+		case PathFiles:
+			if isFile && withinDepth {
+				entries = append(entries, pathKey{typ: PathFiles, value: last})
+			}
+		case PathFull:
+			if withinDepth {
+				entries = append(entries, pathKey{typ: PathFull, value: p})
+			}
+		}
+	}
+	return entries
+}
+
+// WritePaths counts the directory segments, file names and full paths of all results and writes
+// them once all results were received, most frequent first. Entries are written one per line with
+// their count, separated by a tab, ranked per registered domain and prefixed with it, unless the
+// lists are merged. With json, every entry comes with its type too. Results whose extension is
+// blacklisted are skipped.
+func WritePaths(writer io.Writer, results <-chan providers.Result, blacklist mapset.Set[string], opts PathOptions, JSON bool) error {
+	counts := make(map[pathKey]int)
+	for result := range results {
+		u, err := url.Parse(result.URL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		p := u.EscapedPath()
+		if Blacklisted(blacklist, u) {
+			continue
+		}
+
+		var domain string
+		if !opts.Merge {
+			host := strings.ToLower(u.Hostname())
+			if domain = domainutil.Domain(host); domain == "" {
+				domain = host
+			}
+		}
+
+		// count every entry once per url
+		seen := make(map[pathKey]struct{})
+		for _, key := range pathEntries(p, opts) {
+			key.domain = domain
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			counts[key]++
+		}
+	}
+
+	entries := make([]PathResult, 0, len(counts))
+	for key, count := range counts {
+		if uint(count) < opts.MinCount {
+			continue
+		}
+		entries = append(entries, PathResult{Domain: key.domain, Type: key.typ, Value: key.value, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Value < b.Value
+	})
+
+	enc := jsoniter.NewEncoder(writer)
+	for _, entry := range entries {
+		if JSON {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		line := entry.Value + "\t" + strconv.Itoa(entry.Count) + "\n"
+		if entry.Domain != "" {
+			line = entry.Domain + "\t" + line
+		}
+		if _, err := io.WriteString(writer, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
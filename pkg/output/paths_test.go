@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// sendResults returns a closed channel holding a result for every url
+func sendResults(urls ...string) <-chan providers.Result {
+	results := make(chan providers.Result, len(urls))
+	for _, u := range urls {
+		results <- providers.Result{URL: u}
+	}
+	close(results)
+	return results
+}
+
+func TestPathEntries(t *testing.T) {
+	all := []string{PathDirs, PathFiles, PathFull}
+	tests := []struct {
+		path string
+		opts PathOptions
+		want []pathKey
+	}{
+		{path: "/", opts: PathOptions{Types: all}, want: nil},
+		{path: "/admin/login.php", opts: PathOptions{Types: all}, want: []pathKey{
+			{typ: PathDirs, value: "admin"},
+			{typ: PathFiles, value: "login.php"},
+			{typ: PathFull, value: "/admin/login.php"},
+		}},
+		{path: "/v1.2/", opts: PathOptions{Types: []string{PathDirs, PathFiles}}, want: []pathKey{
+			{typ: PathDirs, value: "v1.2"},
+		}},
+		{path: "/a/b/c/x.js", opts: PathOptions{Types: all, MaxDepth: 2}, want: []pathKey{
+			{typ: PathDirs, value: "a"},
+			{typ: PathDirs, value: "b"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := pathEntries(tt.path, tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pathEntries(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWritePaths(t *testing.T) {
+	urls := []string{
+		"https://example.com/admin/login.php",
+		"https://www.example.com/admin/admin/",
+		"https://example.com/static/app.js",
+		"https://example.org/admin/",
+		"https://example.org/logo.PNG",
+	}
+	blacklist := mapset.NewThreadUnsafeSet("", "png")
+	tests := []struct {
+		name string
+		opts PathOptions
+		JSON bool
+		want string
+	}{
+		{
+			name: "per domain",
+			opts: PathOptions{Types: []string{PathDirs}},
+			want: "example.com\tadmin\t2\nexample.com\tstatic\t1\nexample.org\tadmin\t1\n",
+		},
+		{
+			name: "merged",
+			opts: PathOptions{Types: []string{PathDirs, PathFiles}, Merge: true},
+			want: "admin\t3\nstatic\t1\napp.js\t1\nlogin.php\t1\n",
+		},
+		{
+			name: "min count",
+			opts: PathOptions{Types: []string{PathDirs}, Merge: true, MinCount: 2},
+			want: "admin\t3\n",
+		},
+		{
+			name: "json",
+			opts: PathOptions{Types: []string{PathFiles}},
+			JSON: true,
+			want: `{"domain":"example.com","type":"files","value":"app.js","count":1}` + "\n" +
+				`{"domain":"example.com","type":"files","value":"login.php","count":1}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WritePaths(&buf, sendResults(urls...), blacklist, tt.opts, tt.JSON); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WritePaths() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
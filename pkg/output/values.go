@@ -0,0 +1,197 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// DefaultMaxValues is the number of values kept per parameter of an endpoint when none is configured
+const DefaultMaxValues = 100
+
+// unsafeFilename matches the characters replaced in wordlist file names
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// ValueOptions configures the values output mode
+type ValueOptions struct {
+	// Sources lists where values are read from, query and fragment are supported
+	Sources []string
+	// Max is the number of unique values kept per parameter of an endpoint
+	Max uint
+	// Dir is the directory flat wordlists are written to, one per parameter
+	Dir string
+}
+
+// corpus holds the values seen for every parameter of every endpoint, in order of appearance
+type corpus struct {
+	max       int
+	endpoints map[string]map[string][]string
+	seen      map[string]struct{}
+}
+
+// ParamValues returns the name and value pairs of u found in the given sources
+func ParamValues(u *url.URL, sources []string) [][2]string {
+	var pairs [][2]string
+	for _, source := range sources {
+		switch source {
+		case ParamsQuery:
+			pairs = append(pairs, queryValues(u.RawQuery)...)
+		case ParamsFragment:
+			fragment := u.EscapedFragment()
+			if i := strings.IndexByte(fragment, '?'); i >= 0 {
+				fragment = fragment[i+1:]
+			}
+			if strings.Contains(fragment, "=") {
+				pairs = append(pairs, queryValues(fragment)...)
+			}
+		}
+	}
+	return pairs
+}
+
+// queryValues returns the pairs of a form-encoded string that have a value
+func queryValues(query string) [][2]string {
+	var pairs [][2]string
+	for _, pair := range QueryPairs(query) {
+		if pair[1] != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// add records value for the parameter of an endpoint, unless it was seen or the limit was reached
+func (c *corpus) add(endpoint, name, value string) {
+	key := endpoint + "\x00" + name + "\x00" + value
+	if _, ok := c.seen[key]; ok {
+		return
+	}
+
+	params, ok := c.endpoints[endpoint]
+	if !ok {
+		params = make(map[string][]string)
+		c.endpoints[endpoint] = params
+	}
+	if len(params[name]) >= c.max {
+		return
+	}
+	c.seen[key] = struct{}{}
+	params[name] = append(params[name], value)
+}
+
+// WriteValues collects the values of the parameters of every endpoint and writes them once all
+// results were received: a json object {endpoint: {param: [values]}} with json, the unique values
+// one per line otherwise. If a directory is set, a wordlist of the values of every parameter
+// across all endpoints is written to it as well.
+func WriteValues(writer io.Writer, results <-chan providers.Result, blacklist mapset.Set[string], opts ValueOptions, JSON bool) error {
+	c := &corpus{
+		max:       int(opts.Max),
+		endpoints: make(map[string]map[string][]string),
+		seen:      make(map[string]struct{}),
+	}
+	if c.max == 0 {
+		c.max = DefaultMaxValues
+	}
+
+	for result := range results {
+		u, err := url.Parse(result.URL)
+		if err != nil || u.Host == "" || Blacklisted(blacklist, u) {
+			continue
+		}
+		endpoint := strings.ToLower(u.Host) + u.EscapedPath()
+		for _, pair := range ParamValues(u, opts.Sources) {
+			c.add(endpoint, pair[0], pair[1])
+		}
+	}
+
+	if opts.Dir != "" {
+		if err := c.writeWordlists(opts.Dir); err != nil {
+			return err
+		}
+	}
+
+	if JSON {
+		// the standard library configuration sorts map keys
+		return jsoniter.ConfigCompatibleWithStandardLibrary.NewEncoder(writer).Encode(c.endpoints)
+	}
+
+	seen := make(map[string]struct{})
+	for _, endpoint := range sortedKeys(c.endpoints) {
+		params := c.endpoints[endpoint]
+		for _, name := range sortedKeys(params) {
+			for _, value := range params[name] {
+				if _, ok := seen[value]; ok {
+					continue
+				}
+				seen[value] = struct{}{}
+				if _, err := io.WriteString(writer, escapeNewlines(value)+"\n"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeWordlists writes the unique values of every parameter to <dir>/<param>.txt. File names are
+// lowercased so that names differing only in case, such as id and ID, share one wordlist instead
+// of overwriting each other on case-insensitive file systems.
+func (c *corpus) writeWordlists(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("could not create wordlist directory: %v", err)
+	}
+
+	values := make(map[string][]string)
+	seen := make(map[string]struct{})
+	for _, endpoint := range sortedKeys(c.endpoints) {
+		params := c.endpoints[endpoint]
+		for _, name := range sortedKeys(params) {
+			file := strings.ToLower(unsafeFilename.ReplaceAllString(name, "_")) + ".txt"
+			for _, v := range params[name] {
+				key := file + "\x00" + v
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				values[file] = append(values[file], v)
+			}
+		}
+	}
+
+	for file, vs := range values {
+		if err := writeLines(filepath.Join(dir, file), vs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLines(name string, lines []string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		w.WriteString(escapeNewlines(line) + "\n")
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// escapeNewlines escapes the line breaks of values written one per line
+func escapeNewlines(s string) string {
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+}
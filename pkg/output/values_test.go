@@ -0,0 +1,105 @@
+package output
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+func TestParamValues(t *testing.T) {
+	u, err := url.Parse("https://example.com/p;sid=1?a=1&b=&c=x%2Fy#/route?next=/home&d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		sources []string
+		want    [][2]string
+	}{
+		{sources: []string{ParamsQuery}, want: [][2]string{{"a", "1"}, {"c", "x/y"}}},
+		{sources: []string{ParamsFragment}, want: [][2]string{{"next", "/home"}}},
+		{sources: []string{ParamsQuery, ParamsFragment}, want: [][2]string{{"a", "1"}, {"c", "x/y"}, {"next", "/home"}}},
+	}
+	for _, tt := range tests {
+		if got := ParamValues(u, tt.sources); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParamValues(%v) = %q, want %q", tt.sources, got, tt.want)
+		}
+	}
+}
+
+func TestWriteValues(t *testing.T) {
+	urls := []string{
+		"https://example.com/a?id=1&next=/home",
+		"https://example.com/a?id=2&id=1",
+		"https://Example.com/a?id=3",
+		"https://example.com/b?q=multi%0Aline&id=1",
+		"https://example.com/logo.png?id=4",
+	}
+	blacklist := mapset.NewThreadUnsafeSet("", "png")
+	tests := []struct {
+		name string
+		opts ValueOptions
+		JSON bool
+		want string
+	}{
+		{
+			name: "text",
+			opts: ValueOptions{Sources: []string{ParamsQuery}},
+			want: "1\n2\n3\n/home\nmulti\\nline\n",
+		},
+		{
+			name: "json with max",
+			opts: ValueOptions{Sources: []string{ParamsQuery}, Max: 2},
+			JSON: true,
+			want: `{"example.com/a":{"id":["1","2"],"next":["/home"]},"example.com/b":{"id":["1"],"q":["multi\nline"]}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteValues(&buf, sendResults(urls...), blacklist, tt.opts, tt.JSON); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteValues() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteWordlists(t *testing.T) {
+	dir := t.TempDir()
+	urls := []string{
+		"https://example.com/a?id=1&ID=2&user%5Bname%5D=bob",
+		"https://example.com/b?Id=1&id=3",
+	}
+	var buf bytes.Buffer
+	opts := ValueOptions{Sources: []string{ParamsQuery}, Dir: dir}
+	if err := WriteValues(&buf, sendResults(urls...), mapset.NewThreadUnsafeSet(""), opts, false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"id.txt":         "2\n1\n3\n",
+		"user_name_.txt": "bob\n",
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[entry.Name()] = string(data)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wordlists = %q, want %q", got, want)
+	}
+}
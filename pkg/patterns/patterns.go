@@ -0,0 +1,262 @@
+// Package patterns flags urls worth a closer look, such as open redirects or file inclusions,
+// with packs of patterns in the spirit of gf. Packs are yaml or json files matched against
+// parameter names, parameter values, paths and whole urls. The built-in packs can be
+// replaced by packs of the same name in the configured directories, and gf's own json
+// files are read as they are.
+package patterns
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/output"
+	"github.com/lc/gau/v2/pkg/providers"
+	"gopkg.in/yaml.v2"
+)
+
+// All selects every loaded pack
+const All = "all"
+
+//go:embed packs
+var builtin embed.FS
+
+// Pack is a set of patterns. A url matches the pack as soon as one of its patterns matches.
+type Pack struct {
+	// Name defaults to the file name without extension
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// Flags are grep flags as used by gf, -i makes the regular expressions case-insensitive
+	Flags string `json:"flags" yaml:"flags"`
+	// Pattern and Patterns are regular expressions matched against the whole url
+	Pattern  string   `json:"pattern" yaml:"pattern"`
+	Patterns []string `json:"patterns" yaml:"patterns"`
+	// Params are case-insensitive globs matched against the query parameter names
+	Params []string `json:"params" yaml:"params"`
+	// Values are regular expressions matched against the decoded query parameter values
+	Values []string `json:"values" yaml:"values"`
+	// Paths are regular expressions matched against the decoded path
+	Paths []string `json:"paths" yaml:"paths"`
+}
+
+type pack struct {
+	name   string
+	urls   []*regexp.Regexp
+	params []string
+	values []*regexp.Regexp
+	paths  []*regexp.Regexp
+}
+
+type Engine struct {
+	packs []pack
+	// only drops the urls no pack matches
+	only bool
+}
+
+// Load reads the built-in packs, then the packs of every directory in order.
+// A pack replaces any pack of the same name loaded before it.
+func Load(dirs []string) (map[string]Pack, error) {
+	packs := make(map[string]Pack)
+	if err := loadDir(builtin, "packs", packs); err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := loadDir(os.DirFS(dir), ".", packs); err != nil {
+			return nil, err
+		}
+	}
+	return packs, nil
+}
+
+// loadDir reads the yaml and json files of a directory into packs
+func loadDir(fsys fs.FS, dir string, packs map[string]Pack) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		var p Pack
+		if ext == ".json" {
+			err = jsoniter.Unmarshal(data, &p)
+		} else {
+			err = yaml.Unmarshal(data, &p)
+		}
+		if err != nil {
+			return fmt.Errorf("pattern pack %s: %v", entry.Name(), err)
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(entry.Name(), ext)
+		}
+		packs[p.Name] = p
+	}
+	return nil
+}
+
+// New returns an engine running the named packs, or every pack when the names include All
+func New(packs map[string]Pack, names []string, only bool) (*Engine, error) {
+	selected := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if name == All {
+			for name := range packs {
+				selected[name] = struct{}{}
+			}
+			continue
+		}
+		if _, ok := packs[name]; !ok {
+			return nil, fmt.Errorf("unknown pattern pack: %s (available: %s)", name, strings.Join(sortedNames(packs), ", "))
+		}
+		selected[name] = struct{}{}
+	}
+
+	e := &Engine{only: only}
+	for name := range selected {
+		p, err := compile(packs[name])
+		if err != nil {
+			return nil, fmt.Errorf("pattern pack %s: %v", name, err)
+		}
+		e.packs = append(e.packs, p)
+	}
+	sort.Slice(e.packs, func(i, j int) bool {
+		return e.packs[i].name < e.packs[j].name
+	})
+	return e, nil
+}
+
+// compile checks the globs and compiles the regular expressions of a pack
+func compile(p Pack) (pack, error) {
+	c := pack{name: p.Name}
+	prefix := ""
+	if strings.HasPrefix(p.Flags, "-") && strings.Contains(p.Flags, "i") {
+		prefix = "(?i)"
+	}
+
+	var err error
+	urls := p.Patterns
+	if p.Pattern != "" {
+		urls = append([]string{p.Pattern}, urls...)
+	}
+	if c.urls, err = compileAll(prefix, urls); err != nil {
+		return c, err
+	}
+	if c.values, err = compileAll(prefix, p.Values); err != nil {
+		return c, err
+	}
+	if c.paths, err = compileAll(prefix, p.Paths); err != nil {
+		return c, err
+	}
+	for _, param := range p.Params {
+		param = strings.ToLower(param)
+		if _, err = path.Match(param, ""); err != nil {
+			return c, fmt.Errorf("invalid param pattern %q", param)
+		}
+		c.params = append(c.params, param)
+	}
+	return c, nil
+}
+
+func compileAll(prefix string, exprs []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(prefix + expr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Matches returns the sorted names of the packs matching a url
+func (e *Engine) Matches(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	var names, values []string
+	for _, pair := range output.QueryPairs(u.RawQuery) {
+		names = append(names, strings.ToLower(pair[0]))
+		values = append(values, pair[1])
+	}
+
+	var matches []string
+	for _, p := range e.packs {
+		if matchGlob(p.params, names) || matchRegexp(p.values, values) ||
+			matchRegexp(p.paths, []string{u.Path}) || matchRegexp(p.urls, []string{rawURL}) {
+			matches = append(matches, p.name)
+		}
+	}
+	return matches
+}
+
+// Run adds the names of the matching packs to the meta of the results read from in, under
+// "patterns", and passes them on, or only the results that match when so configured.
+// out is closed once in is drained.
+func (e *Engine) Run(in <-chan providers.Result, out chan<- providers.Result) {
+	defer close(out)
+	for result := range in {
+		matches := e.Matches(result.URL)
+		if len(matches) == 0 {
+			if !e.only {
+				out <- result
+			}
+			continue
+		}
+
+		// results may share their meta, so it is copied before being changed
+		meta := make(map[string]string, len(result.Meta)+1)
+		for k, v := range result.Meta {
+			meta[k] = v
+		}
+		meta["patterns"] = strings.Join(matches, ",")
+		result.Meta = meta
+		out <- result
+	}
+}
+
+func matchGlob(patterns, values []string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if ok, _ := path.Match(pattern, value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchRegexp(res []*regexp.Regexp, values []string) bool {
+	for _, re := range res {
+		for _, value := range values {
+			if re.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortedNames(packs map[string]Pack) []string {
+	names := make([]string, 0, len(packs))
+	for name := range packs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
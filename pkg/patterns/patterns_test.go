@@ -0,0 +1,104 @@
+package patterns
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatches(t *testing.T) {
+	packs := map[string]Pack{
+		"redirect": {Name: "redirect", Flags: "-i", Params: []string{"next", "redirect*"}, Values: []string{`^(?:https?:)?//`}},
+		"lfi":      {Name: "lfi", Values: []string{`\.\./`}, Paths: []string{`/etc/passwd`}},
+		"debug":    {Name: "debug", Pattern: `[?&]debug=`, Patterns: []string{`\.php\.bak$`}},
+	}
+	e, err := New(packs, []string{All}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{url: "https://example.com/", want: nil},
+		{url: "https://example.com/login?NEXT=/home", want: []string{"redirect"}},
+		{url: "https://example.com/login?Redirect_To=x", want: []string{"redirect"}},
+		{url: "https://example.com/go?u=%2F%2Fevil.com", want: []string{"redirect"}},
+		{url: "https://example.com/go?a=1;u=//evil.com", want: []string{"redirect"}},
+		{url: "https://example.com/view?file=..%2F..%2Fetc", want: []string{"lfi"}},
+		{url: "https://example.com/static/%2Fetc%2Fpasswd", want: []string{"lfi"}},
+		{url: "https://example.com/x?debug=1&next=../a", want: []string{"debug", "lfi", "redirect"}},
+		{url: "https://example.com/index.php.bak", want: []string{"debug"}},
+		{url: "%zz", want: nil},
+	}
+	for _, tt := range tests {
+		if got := e.Matches(tt.url); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Matches(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	packs := map[string]Pack{"a": {Params: []string{"x"}}, "bad": {Values: []string{"("}}, "glob": {Params: []string{"["}}}
+	tests := []struct {
+		names   []string
+		wantErr bool
+	}{
+		{names: []string{"a"}},
+		{names: []string{"missing"}, wantErr: true},
+		{names: []string{"bad"}, wantErr: true},
+		{names: []string{"glob"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := New(packs, tt.names, false); (err != nil) != tt.wantErr {
+			t.Errorf("New(%v) error = %v, wantErr %v", tt.names, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// replaces the built-in pack
+		"redirect.yaml": "params: [custom]\n",
+		// gf's json format
+		"debug.json": `{"flags": "-iE", "pattern": "debug="}`,
+		"notes.txt":  "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packs, err := Load([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"redirect", "ssrf", "lfi", "sqli", "xss", "rce", "ssti", "idor", "debug"} {
+		if _, ok := packs[name]; !ok {
+			t.Errorf("Load() is missing pack %s", name)
+		}
+	}
+	if _, ok := packs["notes"]; ok {
+		t.Errorf("Load() read a pack from a .txt file")
+	}
+	if got := packs["redirect"].Params; !reflect.DeepEqual(got, []string{"custom"}) {
+		t.Errorf("redirect params = %v, want the replacing pack's", got)
+	}
+	if got := packs["debug"]; got.Name != "debug" || got.Flags != "-iE" || got.Pattern != "debug=" {
+		t.Errorf("debug pack = %+v", got)
+	}
+}
+
+func TestBuiltinPacks(t *testing.T) {
+	packs, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(packs, []string{All}, false); err != nil {
+		t.Errorf("built-in packs do not compile: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package ccindex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	Name = "ccindex"
+
+	// indexFile is the secondary index listing the first key of every shard block
+	indexFile = "cluster.idx"
+	// shardPattern matches the index shards of a Common Crawl collection
+	shardPattern = "cdx-*.gz"
+)
+
+var _ providers.Provider = (*Client)(nil)
+
+// Client reads downloaded Common Crawl index shards, without any network access
+type Client struct {
+	filters providers.Filters
+	config  *providers.Config
+
+	dirs []string
+}
+
+func New(c *providers.Config, filters providers.Filters) *Client {
+	return &Client{
+		filters: filters,
+		config:  c,
+		dirs:    c.CCIndex.Dirs,
+	}
+}
+
+// record is the json part of a CDXJ line
+type record struct {
+	URL      string `json:"url"`
+	Mime     string `json:"mime"`
+	Status   string `json:"status"`
+	Digest   string `json:"digest"`
+	Length   string `json:"length"`
+	Offset   string `json:"offset"`
+	Filename string `json:"filename"`
+}
+
+func (c *Client) Name() string {
+	return Name
+}
+
+// Fetch reads the records of a domain from every configured index directory and
+// sends their urls to a channel. Each url is sent once, with its first capture.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	if len(c.dirs) == 0 {
+		return errors.New("no index directories, set dirs in [ccindex]")
+	}
+
+	r := newSURTRange(domain, c.config.Subdomains(ctx))
+	seen := make(map[string]struct{})
+
+	var errs []error
+	for _, dir := range c.dirs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		logrus.WithFields(logrus.Fields{"provider": Name, "dir": dir}).Infof("fetching %s", domain)
+		if err := c.fetchDir(ctx, dir, r, seen, results); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", dir, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fetchDir reads the shards of dir holding keys within r. Without a cluster.idx,
+// every shard is read from its start.
+func (c *Client) fetchDir(ctx context.Context, dir string, r surtRange, seen map[string]struct{}, results chan providers.Result) error {
+	starts, err := c.shardOffsets(dir, r)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range starts {
+		err := c.readShard(ctx, filepath.Join(dir, s.shard), s.offset, r, seen, results)
+		if errors.Is(err, os.ErrNotExist) {
+			logrus.WithFields(logrus.Fields{"provider": Name}).Warnf("missing shard %s, skipping", s.shard)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", s.shard, err)
+		}
+	}
+	return nil
+}
+
+// shardOffsets returns where to start reading in every shard that may hold keys within r
+func (c *Client) shardOffsets(dir string, r surtRange) ([]block, error) {
+	blocks, err := searchIndex(filepath.Join(dir, indexFile), r)
+	if errors.Is(err, os.ErrNotExist) {
+		logrus.WithFields(logrus.Fields{"provider": Name}).Warnf("no %s in %s, scanning every shard", indexFile, dir)
+		shards, err := filepath.Glob(filepath.Join(dir, shardPattern))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(shards)
+		starts := make([]block, len(shards))
+		for i, shard := range shards {
+			starts[i] = block{shard: filepath.Base(shard)}
+		}
+		return starts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// blocks are sorted, so the first block of a shard is where reading starts
+	var starts []block
+	for _, b := range blocks {
+		if len(starts) == 0 || starts[len(starts)-1].shard != b.shard {
+			starts = append(starts, b)
+		}
+	}
+	return starts, nil
+}
+
+// readShard streams the records of a shard from offset until the keys move past r
+func (c *Client) readShard(ctx context.Context, path string, offset int64, r surtRange, seen map[string]struct{}, results chan providers.Result) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	sc := bufio.NewScanner(gz)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		line := sc.Text()
+		key, rest, ok := strings.Cut(line, " ")
+		if !ok || key < r.start {
+			continue
+		}
+		if key >= r.end {
+			return nil
+		}
+		if !r.match(key) {
+			continue
+		}
+
+		timestamp, data, ok := strings.Cut(rest, " ")
+		if !ok {
+			continue
+		}
+		var rec record
+		if err := jsoniter.UnmarshalFromString(data, &rec); err != nil {
+			return fmt.Errorf("failed to decode record: %s", err)
+		}
+		result := providers.Result{
+			URL:        rec.URL,
+			Source:     Name,
+			Timestamp:  providers.CDXTime(timestamp),
+			StatusCode: rec.Status,
+			MimeType:   rec.Mime,
+			Digest:     rec.Digest,
+			Length:     rec.Length,
+			Meta: map[string]string{
+				"filename": rec.Filename,
+				"offset":   rec.Offset,
+			},
+		}
+		if !c.filters.Match(result) {
+			continue
+		}
+		if _, ok := seen[rec.URL]; ok {
+			continue
+		}
+		seen[rec.URL] = struct{}{}
+		results <- result
+	}
+	return sc.Err()
+}
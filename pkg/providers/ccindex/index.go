@@ -0,0 +1,167 @@
+package ccindex
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// scanThreshold is the size of the index region below which the binary search
+// switches to reading lines sequentially
+const scanThreshold = 64 * 1024
+
+// block is a gzip member of a shard, as listed in cluster.idx
+type block struct {
+	key    string
+	shard  string
+	offset int64
+}
+
+// surtRange holds the SURT prefixes of a domain and the key past all of them
+type surtRange struct {
+	start    string
+	prefixes []string
+	end      string
+}
+
+// newSURTRange returns the keys matching domain, or domain and its subdomains
+func newSURTRange(domain string, subs bool) surtRange {
+	host := strings.TrimPrefix(strings.ToLower(domain), "www.")
+	labels := strings.Split(host, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	surt := strings.Join(labels, ",")
+
+	// ')' sorts before ',' and ':', so the range starts at the host itself and
+	// ends after its ports
+	r := surtRange{
+		start:    surt + ")",
+		prefixes: []string{surt + ")", surt + ":"},
+		end:      surt + ";",
+	}
+	if subs {
+		r.prefixes = append(r.prefixes, surt+",")
+	}
+	return r
+}
+
+// match reports whether a SURT key belongs to the range
+func (r surtRange) match(key string) bool {
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchIndex binary searches a cluster.idx file for the blocks that may hold keys
+// within r: the last block starting before r, and every block starting inside it.
+func searchIndex(path string, r surtRange) ([]block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	lo, hi := int64(0), info.Size()
+	for hi-lo > scanThreshold {
+		mid := lo + (hi-lo)/2
+		_, line, err := lineAfter(f, mid)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line != "" && indexKey(line) < r.start {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	start, _, err := lineAfter(f, lo)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if _, err = f.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var (
+		blocks []block
+		before *block
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		b, ok := parseIndexLine(sc.Text())
+		if !ok {
+			continue
+		}
+		if b.key < r.start {
+			before = &b
+			continue
+		}
+		if b.key >= r.end {
+			break
+		}
+		blocks = append(blocks, b)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if before != nil {
+		blocks = append([]block{*before}, blocks...)
+	}
+	return blocks, nil
+}
+
+// lineAfter returns the first line starting at or after off, and its offset
+func lineAfter(f *os.File, off int64) (int64, string, error) {
+	skip := off > 0
+	if skip {
+		// start one byte early so a line starting at off is found
+		off--
+	}
+	if _, err := f.Seek(off, io.SeekStart); err != nil {
+		return 0, "", err
+	}
+
+	rd := bufio.NewReader(f)
+	if skip {
+		skipped, err := rd.ReadString('\n')
+		if err != nil {
+			return off + int64(len(skipped)), "", err
+		}
+		off += int64(len(skipped))
+	}
+	line, err := rd.ReadString('\n')
+	return off, strings.TrimSuffix(line, "\n"), err
+}
+
+// parseIndexLine parses a cluster.idx line: "<surt> <timestamp>\t<shard>\t<offset>\t<length>\t<id>"
+func parseIndexLine(line string) (block, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 3 {
+		return block{}, false
+	}
+	offset, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return block{}, false
+	}
+	return block{key: indexKey(line), shard: fields[1], offset: offset}, true
+}
+
+// indexKey returns the SURT of an index line, without its timestamp
+func indexKey(line string) string {
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
@@ -0,0 +1,109 @@
+package ccindex
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewSURTRange(t *testing.T) {
+	tests := []struct {
+		domain string
+		subs   bool
+		want   surtRange
+	}{
+		{
+			domain: "Example.com",
+			want:   surtRange{start: "com,example)", prefixes: []string{"com,example)", "com,example:"}, end: "com,example;"},
+		},
+		{
+			domain: "www.example.co.uk",
+			subs:   true,
+			want:   surtRange{start: "uk,co,example)", prefixes: []string{"uk,co,example)", "uk,co,example:", "uk,co,example,"}, end: "uk,co,example;"},
+		},
+	}
+	for _, tt := range tests {
+		if got := newSURTRange(tt.domain, tt.subs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("newSURTRange(%q, %v) = %+v, want %+v", tt.domain, tt.subs, got, tt.want)
+		}
+	}
+}
+
+func TestSURTRangeMatch(t *testing.T) {
+	tests := []struct {
+		key  string
+		subs bool
+		want bool
+	}{
+		{key: "com,example)/", want: true},
+		{key: "com,example:8080)/a", want: true},
+		{key: "com,example,www)/", want: false},
+		{key: "com,example,www)/", subs: true, want: true},
+		{key: "com,examples)/", subs: true, want: false},
+		{key: "com,example-cdn)/", subs: true, want: false},
+	}
+	for _, tt := range tests {
+		if got := newSURTRange("example.com", tt.subs).match(tt.key); got != tt.want {
+			t.Errorf("match(%q) with subs %v = %v, want %v", tt.key, tt.subs, got, tt.want)
+		}
+	}
+}
+
+func TestLineAfter(t *testing.T) {
+	// lines start at offsets 0, 4 and 9
+	f := tempFile(t, "aaa\nbbbb\ncc")
+
+	tests := []struct {
+		off     int64
+		wantOff int64
+		want    string
+		wantErr error
+	}{
+		{off: 0, wantOff: 0, want: "aaa"},
+		{off: 1, wantOff: 4, want: "bbbb"},
+		{off: 4, wantOff: 4, want: "bbbb"},
+		{off: 5, wantOff: 9, want: "cc", wantErr: io.EOF},
+		{off: 9, wantOff: 9, want: "cc", wantErr: io.EOF},
+		{off: 10, wantOff: 11, want: "", wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		off, line, err := lineAfter(f, tt.off)
+		if off != tt.wantOff || line != tt.want || err != tt.wantErr {
+			t.Errorf("lineAfter(%d) = %d, %q, %v, want %d, %q, %v", tt.off, off, line, err, tt.wantOff, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseIndexLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want block
+		ok   bool
+	}{
+		{line: "com,example)/ 20200101000000\tcdx-00001.gz\t1234\t567\t89", want: block{key: "com,example)/", shard: "cdx-00001.gz", offset: 1234}, ok: true},
+		{line: "com,example)/ 20200101000000\tcdx-00001.gz", ok: false},
+		{line: "com,example)/ 20200101000000\tcdx-00001.gz\tx\t567", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseIndexLine(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseIndexLine(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func tempFile(t *testing.T, content string) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cluster.idx")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
@@ -0,0 +1,126 @@
+package commoncrawl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+const (
+	// SelectLatest selects the latest collection, or the latest N with latest:N
+	SelectLatest = "latest"
+	// SelectAll selects every collection
+	SelectAll = "all"
+	// SelectRange selects the collections whose crawl dates overlap --from/--to
+	SelectRange = "range"
+)
+
+// collectionID matches the weekly Common Crawl collection ids, e.g. CC-MAIN-2023-50
+var collectionID = regexp.MustCompile(`^CC-MAIN-(\d{4})-(\d{2})$`)
+
+// ValidSelector reports whether s is a valid collection selector.
+func ValidSelector(s string) bool {
+	switch {
+	case s == SelectLatest, s == SelectAll, s == SelectRange:
+		return true
+	case strings.HasPrefix(s, SelectLatest+":"):
+		n, err := strconv.Atoi(strings.TrimPrefix(s, SelectLatest+":"))
+		return err == nil && n > 0
+	default:
+		return strings.HasPrefix(s, "CC-MAIN-")
+	}
+}
+
+// selectCollections returns the collections matching selectors, newest first and
+// without duplicates. The collinfo listing is expected to be ordered newest first.
+func selectCollections(all apiResult, selectors []string, filters providers.Filters) (apiResult, error) {
+	if len(selectors) == 0 {
+		selectors = []string{SelectLatest}
+	}
+
+	picked := make(map[string]bool)
+	for _, selector := range selectors {
+		switch {
+		case selector == SelectAll:
+			for _, coll := range all {
+				picked[coll.ID] = true
+			}
+		case selector == SelectLatest || strings.HasPrefix(selector, SelectLatest+":"):
+			n := 1
+			if s := strings.TrimPrefix(selector, SelectLatest+":"); s != selector {
+				var err error
+				if n, err = strconv.Atoi(s); err != nil {
+					return nil, fmt.Errorf("invalid collection selector: %s", selector)
+				}
+			}
+			for i := 0; i < n && i < len(all); i++ {
+				picked[all[i].ID] = true
+			}
+		case selector == SelectRange:
+			from, to := filterRange(filters)
+			for _, coll := range all {
+				start, end, ok := coll.dates()
+				if ok && !start.After(to) && !end.Before(from) {
+					picked[coll.ID] = true
+				}
+			}
+		default:
+			found := false
+			for _, coll := range all {
+				if coll.ID == selector {
+					picked[coll.ID] = true
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unknown commoncrawl collection: %s", selector)
+			}
+		}
+	}
+
+	var selected apiResult
+	for _, coll := range all {
+		if picked[coll.ID] {
+			selected = append(selected, coll)
+		}
+	}
+	return selected, nil
+}
+
+// filterRange returns the time range covered by the from/to filters.
+// Unset bounds are left open.
+func filterRange(filters providers.Filters) (time.Time, time.Time) {
+	from := time.Time{}
+	to := time.Now().AddDate(100, 0, 0)
+	if t, err := time.Parse("200601", filters.From); err == nil {
+		from = t
+	}
+	if t, err := time.Parse("200601", filters.To); err == nil {
+		// include the whole month
+		to = t.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	}
+	return from, to
+}
+
+// dates returns the time range a collection was crawled in. Collections without
+// dates in collinfo are assumed to span the week in their id.
+func (c collection) dates() (time.Time, time.Time, bool) {
+	start, errFrom := time.Parse(time.RFC3339, strings.TrimSuffix(c.From, "Z")+"Z")
+	end, errTo := time.Parse(time.RFC3339, strings.TrimSuffix(c.To, "Z")+"Z")
+	if errFrom == nil && errTo == nil {
+		return start, end, true
+	}
+
+	m := collectionID.FindStringSubmatch(c.ID)
+	if m == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, (week-1)*7)
+	return start, start.AddDate(0, 0, 7), true
+}
@@ -0,0 +1,96 @@
+package commoncrawl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+func TestValidSelector(t *testing.T) {
+	tests := map[string]bool{
+		"latest":          true,
+		"latest:3":        true,
+		"latest:0":        false,
+		"latest:x":        false,
+		"all":             true,
+		"range":           true,
+		"CC-MAIN-2023-50": true,
+		"2023-50":         false,
+	}
+	for selector, want := range tests {
+		if got := ValidSelector(selector); got != want {
+			t.Errorf("ValidSelector(%q) = %v, want %v", selector, got, want)
+		}
+	}
+}
+
+func TestSelectCollections(t *testing.T) {
+	all := apiResult{
+		{ID: "CC-MAIN-2024-10", From: "2024-02-20T00:00:00", To: "2024-03-05T00:00:00"},
+		{ID: "CC-MAIN-2023-50"},
+		{ID: "CC-MAIN-2023-23"},
+		{ID: "CC-MAIN-2019-04"},
+	}
+	ids := func(colls apiResult) []string {
+		var ids []string
+		for _, coll := range colls {
+			ids = append(ids, coll.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name      string
+		selectors []string
+		filters   providers.Filters
+		want      []string
+		wantErr   bool
+	}{
+		{name: "default", want: []string{"CC-MAIN-2024-10"}},
+		{name: "latest n", selectors: []string{"latest:2"}, want: []string{"CC-MAIN-2024-10", "CC-MAIN-2023-50"}},
+		{name: "latest beyond the list", selectors: []string{"latest:10"}, want: ids(all)},
+		{name: "all", selectors: []string{"all"}, want: ids(all)},
+		{name: "ids newest first without duplicates", selectors: []string{"CC-MAIN-2019-04", "latest", "CC-MAIN-2024-10"}, want: []string{"CC-MAIN-2024-10", "CC-MAIN-2019-04"}},
+		{name: "range", selectors: []string{"range"}, filters: providers.Filters{From: "202301", To: "202312"}, want: []string{"CC-MAIN-2023-50", "CC-MAIN-2023-23"}},
+		{name: "open range", selectors: []string{"range"}, filters: providers.Filters{From: "202403"}, want: []string{"CC-MAIN-2024-10"}},
+		{name: "unknown id", selectors: []string{"CC-MAIN-2010-01"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectCollections(all, tt.selectors, tt.filters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectCollections() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(ids(got), tt.want) {
+				t.Errorf("selectCollections() = %v, want %v", ids(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRange(t *testing.T) {
+	from, to := filterRange(providers.Filters{From: "202301", To: "202302"})
+	if want := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("filterRange() from = %s, want %s", from, want)
+	}
+	if want := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond); !to.Equal(want) {
+		t.Errorf("filterRange() to = %s, want %s", to, want)
+	}
+
+	from, to = filterRange(providers.Filters{})
+	if !from.IsZero() || !to.After(time.Now()) {
+		t.Errorf("filterRange() of unset filters = %s, %s, want an open range", from, to)
+	}
+}
+
+func TestCollectionDates(t *testing.T) {
+	start, end, ok := collection{ID: "CC-MAIN-2023-02"}.dates()
+	if want := time.Date(2023, time.January, 8, 0, 0, 0, 0, time.UTC); !ok || !start.Equal(want) || !end.Equal(want.AddDate(0, 0, 7)) {
+		t.Errorf("dates() = %s, %s, %v, want the second week of 2023", start, end, ok)
+	}
+	if _, _, ok := (collection{ID: "CC-NEWS"}).dates(); ok {
+		t.Error("dates() of a collection without dates or week id reported a range")
+	}
+}
@@ -0,0 +1,153 @@
+package commoncrawl
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/httpclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultCollInfoURL lists the available Common Crawl collections
+	DefaultCollInfoURL = "https://index.commoncrawl.org/collinfo.json"
+	// DefaultCacheTTL is how long the collection list is cached on disk
+	DefaultCacheTTL = 24 * time.Hour
+
+	// upper bound for the delay between two collinfo attempts
+	maxBackoff = 30 * time.Second
+)
+
+// loadCollections discovers and selects the collections to query the first time it is called.
+// Once discovery has failed, the provider is unavailable and the same error is returned.
+func (c *Client) loadCollections(ctx context.Context) error {
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
+
+	if c.collections != nil || c.initErr != nil {
+		return c.initErr
+	}
+
+	all, err := c.collInfo(ctx)
+	if err == nil && len(all) == 0 {
+		err = errors.New("failed to grab latest commoncrawl index")
+	}
+	if err == nil {
+		c.collections, err = selectCollections(all, c.selectors, c.filters)
+	}
+	if err == nil && len(c.collections) == 0 {
+		err = errors.New("no commoncrawl collections matched the selection")
+	}
+	if err != nil {
+		c.initErr = fmt.Errorf("commoncrawl unavailable: %v", err)
+		return c.initErr
+	}
+
+	logrus.WithFields(logrus.Fields{"provider": Name}).Infof("using %d collection(s)", len(c.collections))
+	return nil
+}
+
+// collInfo returns the list of collections, from the disk cache while it is fresh and
+// from the collinfo url otherwise. A stale cache is used should every request fail.
+func (c *Client) collInfo(ctx context.Context) (apiResult, error) {
+	cacheFile := c.cacheFile()
+
+	cached, modified, cacheErr := readCollInfoCache(cacheFile)
+	if cacheErr == nil && time.Since(modified) < c.cacheTTL {
+		return cached, nil
+	}
+
+	resp, err := c.fetchCollInfo(ctx)
+	if err != nil {
+		if cacheErr == nil {
+			logrus.WithFields(logrus.Fields{"provider": Name}).Warnf("using stale collection list: %v", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	var r apiResult
+	if err = jsoniter.Unmarshal(resp, &r); err != nil {
+		return nil, err
+	}
+
+	if cacheFile != "" {
+		if err := writeCollInfoCache(cacheFile, resp); err != nil {
+			logrus.WithFields(logrus.Fields{"provider": Name}).Infof("could not cache collection list: %v", err)
+		}
+	}
+	return r, nil
+}
+
+// fetchCollInfo requests the collinfo url, backing off exponentially between attempts
+func (c *Client) fetchCollInfo(ctx context.Context) ([]byte, error) {
+	backoff := time.Second
+	var err error
+	for attempt := uint(0); attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			logrus.WithFields(logrus.Fields{"provider": Name, "attempt": attempt}).Infof("retrying collection list in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		var resp []byte
+		resp, err = httpclient.MakeRequest(c.config.Client, c.collInfoURL, 0, c.config.Timeout)
+		if err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
+
+// cacheFile returns the cache location for the configured collinfo url, so that
+// mirrors don't share a cache. It returns an empty string if there is no cache dir.
+func (c *Client) cacheFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum([]byte(c.collInfoURL))
+	return filepath.Join(dir, "gau", "collinfo-"+hex.EncodeToString(sum[:4])+".json")
+}
+
+func readCollInfoCache(name string) (apiResult, time.Time, error) {
+	if name == "" {
+		return nil, time.Time{}, os.ErrNotExist
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var r apiResult
+	if err = jsoniter.Unmarshal(data, &r); err != nil {
+		return nil, time.Time{}, err
+	}
+	if len(r) == 0 {
+		return nil, time.Time{}, errors.New("empty collection cache")
+	}
+	return r, info.ModTime(), nil
+}
+
+func writeCollInfoCache(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
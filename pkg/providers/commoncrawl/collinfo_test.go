@@ -0,0 +1,81 @@
+package commoncrawl
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadCollectionsLazy(t *testing.T) {
+	var requests int32
+	c, _ := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`[{"id": "CC-MAIN-2024-10"}]`))
+	})
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("New() made %d requests, want none", n)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := c.loadCollections(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 || len(c.collections) != 1 {
+		t.Errorf("loadCollections() made %d requests for %d collections, want 1 request", n, len(c.collections))
+	}
+
+	// a fresh client reads the cached list
+	c.collections = nil
+	if err := c.loadCollections(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("loadCollections() made %d requests with a fresh cache, want 1", n)
+	}
+}
+
+func TestLoadCollectionsFailure(t *testing.T) {
+	var requests int32
+	c, _ := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	err := c.loadCollections(context.Background())
+	if err == nil {
+		t.Fatal("loadCollections() returned no error for an unavailable collinfo")
+	}
+	// the failure is remembered, the provider is not retried for every domain
+	if again := c.loadCollections(context.Background()); again != err {
+		t.Errorf("loadCollections() = %v on the second call, want %v", again, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("loadCollections() made %d requests, want 1", n)
+	}
+}
+
+func TestLoadCollectionsStaleCache(t *testing.T) {
+	c, _ := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	cacheFile := c.cacheFile()
+	if err := writeCollInfoCache(cacheFile, []byte(`[{"id": "CC-MAIN-2023-50"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * DefaultCacheTTL)
+	if err := os.Chtimes(cacheFile, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.loadCollections(context.Background()); err != nil {
+		t.Fatalf("loadCollections() error = %v, want the stale cache to be used", err)
+	}
+	if len(c.collections) != 1 || c.collections[0].ID != "CC-MAIN-2023-50" {
+		t.Errorf("loadCollections() selected %v, want the cached collection", c.collections)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/lc/gau/v2/pkg/httpclient"
@@ -15,36 +17,67 @@ import (
 
 const (
 	Name = "commoncrawl"
+
+	// DefaultConcurrency is the number of pages fetched at once when none is configured
+	DefaultConcurrency = 4
 )
 
 // verify interface compliance
-var _ providers.Provider = (*Client)(nil)
+var _ providers.Paginator = (*Client)(nil)
 
 // Client is the structure that holds the Filters and the Client's configuration
 type Client struct {
 	filters providers.Filters
 	config  *providers.Config
 
-	apiURL string
+	collInfoURL string
+	cacheTTL    time.Duration
+	selectors   []string
+	concurrency uint
+
+	// collections are discovered on the first fetch
+	initMu      sync.Mutex
+	initErr     error
+	collections apiResult
 }
 
-func New(c *providers.Config, filters providers.Filters) (*Client, error) {
-	// Fetch the list of available CommonCrawl Api URLs.
-	resp, err := httpclient.MakeRequest(c.Client, "http://index.commoncrawl.org/collinfo.json", c.MaxRetries, c.Timeout)
-	if err != nil {
-		return nil, err
-	}
+// domainPages maps the pages of a domain onto the collections they belong to,
+// and tracks the urls already sent so results are deduplicated across collections.
+// It is kept in the PageState of a single Pages call.
+type domainPages struct {
+	collections []collectionPages
+	pages       uint
 
-	var r apiResult
-	if err = jsoniter.Unmarshal(resp, &r); err != nil {
-		return nil, err
-	}
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
 
-	if len(r) == 0 {
-		return nil, errors.New("failed to grab latest commoncrawl index")
-	}
+type collectionPages struct {
+	collection collection
+	pages      uint
+}
 
-	return &Client{config: c, filters: filters, apiURL: r[0].API}, nil
+// New returns a commoncrawl Client. The list of collections is not fetched
+// until the first domain is.
+func New(c *providers.Config, filters providers.Filters) *Client {
+	client := &Client{
+		config:      c,
+		filters:     filters,
+		collInfoURL: c.CommonCrawl.CollInfo,
+		cacheTTL:    time.Duration(c.CommonCrawl.CacheTTL) * time.Hour,
+		selectors:   c.CommonCrawl.Collections,
+		concurrency: c.CommonCrawl.Concurrency,
+	}
+	if client.collInfoURL == "" {
+		client.collInfoURL = DefaultCollInfoURL
+	}
+	if client.cacheTTL == 0 {
+		client.cacheTTL = DefaultCacheTTL
+	}
+	if client.concurrency == 0 {
+		client.concurrency = DefaultConcurrency
+	}
+	return client
 }
 
 func (c *Client) Name() string {
@@ -53,59 +86,182 @@ func (c *Client) Name() string {
 
 // Fetch fetches all urls for a given domain and sends them to a channel.
 // It returns an error should one occur.
-func (c *Client) Fetch(ctx context.Context, domain string, results chan string) error {
-	p, err := c.getPagination(domain)
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	ctx = providers.WithPageState(ctx)
+	pages, err := c.Pages(ctx, domain)
 	if err != nil {
 		return err
 	}
-	// 0 pages means no results
-	if p.Pages == 0 {
-		logrus.WithFields(logrus.Fields{"provider": Name}).Infof("no results for %s", domain)
-		return nil
-	}
 
-	for page := uint(0); page < p.Pages; page++ {
+	for page := uint(0); page < pages; page++ {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
-			logrus.WithFields(logrus.Fields{"provider": Name, "page": page}).Infof("fetching %s", domain)
-			apiURL := c.formatURL(domain, page)
-			resp, err := httpclient.MakeRequest(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout)
-			if err != nil {
-				return fmt.Errorf("failed to fetch commoncrawl(%d): %s", page, err)
+			if err = c.FetchPage(ctx, domain, page, results); err != nil {
+				return err
 			}
+		}
+	}
+	return nil
+}
+
+// Pages returns the number of pages for domain across all selected collections.
+// The page counts of the collections are fetched concurrently, and kept in the
+// PageState of ctx for FetchPage.
+func (c *Client) Pages(ctx context.Context, domain string) (uint, error) {
+	state := providers.PageStateFrom(ctx)
+	if state == nil {
+		return 0, fmt.Errorf("no page state for %s", domain)
+	}
+	if err := c.loadCollections(ctx); err != nil {
+		return 0, err
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		errs  []error
+		limit = make(chan struct{}, c.MaxConcurrency())
+		pages = make([]collectionPages, len(c.collections))
+	)
 
-			sc := bufio.NewScanner(bytes.NewReader(resp))
-			for sc.Scan() {
-				var res apiResponse
-				if err := jsoniter.Unmarshal(sc.Bytes(), &res); err != nil {
-					return fmt.Errorf("failed to decode commoncrawl result:  %s", err)
-				}
-				if res.Error != "" {
-					return fmt.Errorf("received an error from commoncrawl: %s", res.Error)
-				}
-
-				results <- res.URL
+	for i, coll := range c.collections {
+		wg.Add(1)
+		go func(i int, coll collection) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			p, err := c.getPagination(ctx, coll, domain)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %v", coll.ID, err))
+				mu.Unlock()
+				return
 			}
+			pages[i] = collectionPages{collection: coll, pages: p.Pages}
+		}(i, coll)
+	}
+	wg.Wait()
+
+	if len(errs) == len(c.collections) {
+		return 0, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		logrus.WithFields(logrus.Fields{"provider": Name}).Warnf("%s - failed to fetch pagination: %v", domain, err)
+	}
+
+	dp := &domainPages{seen: make(map[string]struct{})}
+	for _, p := range pages {
+		if p.pages == 0 {
+			continue
+		}
+		dp.collections = append(dp.collections, p)
+		dp.pages += p.pages
+	}
+
+	// 0 pages means no results
+	if dp.pages == 0 {
+		logrus.WithFields(logrus.Fields{"provider": Name}).Infof("no results for %s", domain)
+		return 0, nil
+	}
+
+	state.Store(dp)
+	return dp.pages, nil
+}
+
+// FetchPage fetches a single page of results for domain. Pages are numbered
+// across all selected collections, as returned by the Pages call whose PageState ctx carries.
+func (c *Client) FetchPage(ctx context.Context, domain string, page uint, results chan providers.Result) error {
+	var dp *domainPages
+	if state := providers.PageStateFrom(ctx); state != nil {
+		dp, _ = state.Load().(*domainPages)
+	}
+	if dp == nil {
+		return fmt.Errorf("no pagination for %s", domain)
+	}
+
+	coll, local, ok := dp.locate(page)
+	if !ok {
+		return fmt.Errorf("page %d out of range", page)
+	}
+
+	logrus.WithFields(logrus.Fields{"provider": Name, "collection": coll.ID, "page": local}).Infof("fetching %s", domain)
+	apiURL := c.formatURL(ctx, coll, domain, local)
+	resp, err := httpclient.MakeRequest(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to fetch commoncrawl(%s, %d): %s", coll.ID, local, err)
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(resp))
+	for sc.Scan() {
+		var res apiResponse
+		if err := jsoniter.Unmarshal(sc.Bytes(), &res); err != nil {
+			return fmt.Errorf("failed to decode commoncrawl result:  %s", err)
+		}
+		if res.Error != "" {
+			return fmt.Errorf("received an error from commoncrawl: %s", res.Error)
+		}
+
+		if !dp.firstSeen(res.URL) {
+			continue
+		}
+
+		results <- providers.Result{
+			URL:        res.URL,
+			Source:     Name,
+			Timestamp:  providers.CDXTime(res.Timestamp),
+			StatusCode: res.Status,
+			MimeType:   res.Mime,
+			Digest:     res.Digest,
+			Length:     res.Length,
+			Meta:       map[string]string{"crawl": coll.ID},
 		}
 	}
 	return nil
 }
 
-func (c *Client) formatURL(domain string, page uint) string {
-	if c.config.IncludeSubdomains {
+// MaxConcurrency returns the maximum number of pages fetched at once.
+func (c *Client) MaxConcurrency() uint {
+	return c.concurrency
+}
+
+// locate returns the collection and the page within it for a global page number
+func (dp *domainPages) locate(page uint) (collection, uint, bool) {
+	for _, p := range dp.collections {
+		if page < p.pages {
+			return p.collection, page, true
+		}
+		page -= p.pages
+	}
+	return collection{}, 0, false
+}
+
+// firstSeen records u for the domain and reports whether it was new
+func (dp *domainPages) firstSeen(u string) bool {
+	dp.mu.Lock()
+	defer dp.mu.Unlock()
+	if _, ok := dp.seen[u]; ok {
+		return false
+	}
+	dp.seen[u] = struct{}{}
+	return true
+}
+
+func (c *Client) formatURL(ctx context.Context, coll collection, domain string, page uint) string {
+	if c.config.Subdomains(ctx) {
 		domain = "*." + domain
 	}
 
 	filterParams := c.filters.GetParameters(false)
 
-	return fmt.Sprintf("%s?url=%s/*&output=json&fl=url&page=%d", c.apiURL, domain, page) + filterParams
+	return fmt.Sprintf("%s?url=%s/*&output=json&fl=url,timestamp,status,mime,digest,length&page=%d", coll.API, domain, page) + filterParams
 }
 
 // Fetch the number of pages.
-func (c *Client) getPagination(domain string) (r paginationResult, err error) {
-	url := fmt.Sprintf("%s&showNumPages=true", c.formatURL(domain, 0))
+func (c *Client) getPagination(ctx context.Context, coll collection, domain string) (r paginationResult, err error) {
+	url := fmt.Sprintf("%s&showNumPages=true", c.formatURL(ctx, coll, domain, 0))
 	var resp []byte
 
 	resp, err = httpclient.MakeRequest(c.config.Client, url, c.config.MaxRetries, c.config.Timeout)
@@ -0,0 +1,68 @@
+package commoncrawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/valyala/fasthttp"
+)
+
+// newTestClient returns a client for collections served by handler, with its own collinfo cache
+func newTestClient(t *testing.T, selectors []string, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(&providers.Config{
+		Timeout: 5,
+		Client:  &fasthttp.Client{},
+		CommonCrawl: providers.CommonCrawl{
+			CollInfo:    srv.URL + "/collinfo.json",
+			Collections: selectors,
+		},
+	}, providers.Filters{}), srv
+}
+
+func TestFetchDedup(t *testing.T) {
+	var baseURL string
+	c, srv := newTestClient(t, []string{"all"}, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/collinfo.json":
+			fmt.Fprintf(w, `[{"id": "CC-MAIN-2024-10", "cdx-api": "%[1]s/new"}, {"id": "CC-MAIN-2023-50", "cdx-api": "%[1]s/old"}]`, baseURL)
+		case r.URL.Query().Get("showNumPages") == "true":
+			w.Write([]byte(`{"pages": 1}`))
+		case r.URL.Path == "/new":
+			w.Write([]byte(`{"url": "https://example.com/a"}` + "\n" + `{"url": "https://example.com/b"}` + "\n"))
+		default:
+			w.Write([]byte(`{"url": "https://example.com/b"}` + "\n" + `{"url": "https://example.com/c"}` + "\n"))
+		}
+	})
+	baseURL = srv.URL
+
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+
+	got := make(map[string]string)
+	for result := range results {
+		if _, ok := got[result.URL]; ok {
+			t.Errorf("Fetch() sent %s twice", result.URL)
+		}
+		got[result.URL] = result.Meta["crawl"]
+	}
+	want := map[string]string{
+		"https://example.com/a": "CC-MAIN-2024-10",
+		"https://example.com/b": "CC-MAIN-2024-10",
+		"https://example.com/c": "CC-MAIN-2023-50",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() sent %v, want %v", got, want)
+	}
+}
@@ -1,8 +1,13 @@
 package commoncrawl
 
 type apiResponse struct {
-	URL   string `json:"url"`
-	Error string `json:"error"`
+	URL       string `json:"url"`
+	Timestamp string `json:"timestamp"`
+	Status    string `json:"status"`
+	Mime      string `json:"mime"`
+	Digest    string `json:"digest"`
+	Length    string `json:"length"`
+	Error     string `json:"error"`
 }
 
 type paginationResult struct {
@@ -11,6 +16,11 @@ type paginationResult struct {
 	Pages    uint `json:"pages"`
 }
 
-type apiResult []struct {
-	API string `json:"cdx-api"`
+type collection struct {
+	ID   string `json:"id"`
+	API  string `json:"cdx-api"`
+	From string `json:"from"`
+	To   string `json:"to"`
 }
+
+type apiResult []collection
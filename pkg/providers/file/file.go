@@ -0,0 +1,125 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const Name = "file"
+
+var _ providers.Provider = (*Client)(nil)
+
+// Client reads urls collected by other tools from local files: plain text
+// lists, HAR files and Burp Suite XML exports.
+type Client struct {
+	filters providers.Filters
+	config  *providers.Config
+
+	paths []string
+}
+
+func New(c *providers.Config, filters providers.Filters) *Client {
+	return &Client{
+		filters: filters,
+		config:  c,
+		paths:   c.File.Paths,
+	}
+}
+
+func (c *Client) Name() string {
+	return Name
+}
+
+// Fetch reads every configured file and sends the urls on a domain to a channel.
+// Each url is sent once.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("no files found, set paths in [file]")
+	}
+
+	seen := make(map[string]struct{})
+	var errs []error
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return nil
+		}
+		logrus.WithFields(logrus.Fields{"provider": Name, "file": file}).Infof("fetching %s", domain)
+		if err := c.read(file, domain, c.config.Subdomains(ctx), seen, results); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", file, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// files expands the configured globs
+func (c *Client) files() ([]string, error) {
+	var files []string
+	for _, pattern := range c.paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %v", pattern, err)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// read parses a file and sends its in-scope urls
+func (c *Client) read(file, domain string, subs bool, seen map[string]struct{}, results chan providers.Result) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	head, _ := br.Peek(512)
+	format := detectFormat(file, head)
+
+	emit := func(result providers.Result) {
+		if result.URL == "" || !providers.InScope(result.URL, domain, subs) {
+			return
+		}
+		if !c.filters.Match(result) {
+			return
+		}
+		if _, ok := seen[result.URL]; ok {
+			return
+		}
+		seen[result.URL] = struct{}{}
+
+		result.Source = Name
+		if result.Meta == nil {
+			result.Meta = make(map[string]string)
+		}
+		result.Meta["file"] = file
+		result.Meta["format"] = format
+		results <- result
+	}
+
+	switch format {
+	case formatHAR:
+		return parseHAR(br, emit)
+	case formatBurp:
+		return parseBurp(br, emit)
+	default:
+		return parseText(br, emit)
+	}
+}
@@ -0,0 +1,48 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+func TestFetch(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt": "https://example.com/a\nhttps://www.example.com/b\nhttps://notexample.com/c\n",
+		"b.har": `{"log": {"entries": [{"request": {"method": "GET", "url": "https://example.com/a"}, "response": {}},
+			{"request": {"method": "GET", "url": "https://example.com/d"}, "response": {}}]}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := New(&providers.Config{
+		IncludeSubdomains: true,
+		File:              providers.File{Paths: []string{filepath.Join(dir, "*")}},
+	}, providers.Filters{})
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+
+	got := make(map[string]string)
+	for r := range results {
+		got[r.URL] = filepath.Base(r.Meta["file"]) + ":" + r.Meta["format"]
+	}
+	want := map[string]string{
+		"https://example.com/a":     "a.txt:text",
+		"https://www.example.com/b": "a.txt:text",
+		"https://example.com/d":     "b.har:har",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() sent %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package file
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+const (
+	formatText = "text"
+	formatHAR  = "har"
+	formatBurp = "burp"
+)
+
+// detectFormat guesses the format of a file from its name and first bytes
+func detectFormat(name string, head []byte) string {
+	name = strings.ToLower(name)
+	trimmed := strings.TrimSpace(string(head))
+	switch {
+	case strings.HasSuffix(name, ".har") || strings.HasPrefix(trimmed, "{"):
+		return formatHAR
+	case strings.HasSuffix(name, ".xml") || strings.HasPrefix(trimmed, "<"):
+		return formatBurp
+	}
+	return formatText
+}
+
+// parseText reads a list of urls, one per line. Blank lines and lines starting with # are skipped.
+func parseText(r io.Reader, emit func(providers.Result)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		emit(providers.Result{URL: line})
+	}
+	return sc.Err()
+}
+
+// harLog is the part of a HAR file holding the recorded requests
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			StartedDateTime string `json:"startedDateTime"`
+			Request         struct {
+				Method string `json:"method"`
+				URL    string `json:"url"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Content struct {
+					MimeType string `json:"mimeType"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// parseHAR reads the requests recorded in a HAR file
+func parseHAR(r io.Reader, emit func(providers.Result)) error {
+	var har harLog
+	if err := jsoniter.NewDecoder(r).Decode(&har); err != nil {
+		return err
+	}
+
+	for _, entry := range har.Log.Entries {
+		result := providers.Result{
+			URL:      entry.Request.URL,
+			MimeType: mimeType(entry.Response.Content.MimeType),
+			Meta:     map[string]string{"method": entry.Request.Method},
+		}
+		// requests that never got a response are recorded with status 0
+		if entry.Response.Status > 0 {
+			result.StatusCode = strconv.Itoa(entry.Response.Status)
+		}
+		if t, err := time.Parse(time.RFC3339Nano, entry.StartedDateTime); err == nil {
+			result.Timestamp = t.UTC().Format(time.RFC3339)
+		}
+		emit(result)
+	}
+	return nil
+}
+
+// burpItem is an entry of a Burp Suite "save items" export
+type burpItem struct {
+	Time     string `xml:"time"`
+	URL      string `xml:"url"`
+	Method   string `xml:"method"`
+	Status   string `xml:"status"`
+	MimeType string `xml:"mimetype"`
+}
+
+// parseBurp reads the items of a Burp Suite XML export, decoding one item at a time
+func parseBurp(r io.Reader, emit func(providers.Result)) error {
+	decoder := xml.NewDecoder(r)
+	// exports declare their encoding, the urls are ascii either way
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "item" {
+			continue
+		}
+
+		var item burpItem
+		if err := decoder.DecodeElement(&item, &start); err != nil {
+			return err
+		}
+		result := providers.Result{
+			URL:        strings.TrimSpace(item.URL),
+			StatusCode: strings.TrimSpace(item.Status),
+			Meta:       map[string]string{"method": item.Method},
+		}
+		// burp reports a category such as HTML or script instead of the mime type
+		if item.MimeType != "" {
+			result.Meta["burp_mimetype"] = item.MimeType
+		}
+		if t, err := time.Parse(time.UnixDate, item.Time); err == nil {
+			result.Timestamp = t.UTC().Format(time.RFC3339)
+		}
+		emit(result)
+	}
+}
+
+// mimeType strips the parameters of a content type
+func mimeType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
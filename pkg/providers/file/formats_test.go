@@ -0,0 +1,119 @@
+package file
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// parse runs a parser over input and returns the results it emitted
+func parse(parser func(io.Reader, func(providers.Result)) error, input string) ([]providers.Result, error) {
+	var results []providers.Result
+	err := parser(strings.NewReader(input), func(r providers.Result) {
+		results = append(results, r)
+	})
+	return results, err
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name, head, want string
+	}{
+		{name: "urls.txt", head: "https://example.com/\n", want: formatText},
+		{name: "capture.HAR", head: "", want: formatHAR},
+		{name: "export", head: "  {\"log\": {}}", want: formatHAR},
+		{name: "items.xml", head: "", want: formatBurp},
+		{name: "items", head: "<?xml version=\"1.0\"?>", want: formatBurp},
+	}
+	for _, tt := range tests {
+		if got := detectFormat(tt.name, []byte(tt.head)); got != tt.want {
+			t.Errorf("detectFormat(%q, %q) = %q, want %q", tt.name, tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestParseText(t *testing.T) {
+	got, err := parse(parseText, "# crawl\nhttps://example.com/a\n\n  example.com/b  \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []providers.Result{{URL: "https://example.com/a"}, {URL: "example.com/b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseText() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseHAR(t *testing.T) {
+	har := `{"log": {"entries": [
+		{"startedDateTime": "2023-05-01T10:20:30.123+02:00",
+		 "request": {"method": "POST", "url": "https://example.com/api"},
+		 "response": {"status": 201, "content": {"mimeType": "application/json; charset=utf-8"}}},
+		{"startedDateTime": "",
+		 "request": {"method": "GET", "url": "https://example.com/blocked"},
+		 "response": {"status": 0, "content": {}}}
+	]}}`
+	got, err := parse(parseHAR, har)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []providers.Result{
+		{
+			URL:        "https://example.com/api",
+			StatusCode: "201",
+			MimeType:   "application/json",
+			Timestamp:  "2023-05-01T08:20:30Z",
+			Meta:       map[string]string{"method": "POST"},
+		},
+		{
+			URL:  "https://example.com/blocked",
+			Meta: map[string]string{"method": "GET"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHAR() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parse(parseHAR, `{"log": `); err == nil {
+		t.Error("parseHAR() of a truncated file returned no error")
+	}
+}
+
+func TestParseBurp(t *testing.T) {
+	burp := `<?xml version="1.0" encoding="ISO-8859-1"?>
+<items burpVersion="2023.1">
+  <item>
+    <time>Mon May 01 10:20:30 UTC 2023</time>
+    <url><![CDATA[https://example.com/login?next=/]]></url>
+    <method><![CDATA[GET]]></method>
+    <status>302</status>
+    <mimetype>HTML</mimetype>
+    <request base64="true"><![CDATA[R0VUIC8=]]></request>
+  </item>
+  <item>
+    <url><![CDATA[ https://example.com/app.js ]]></url>
+    <method>GET</method>
+  </item>
+</items>`
+	got, err := parse(parseBurp, burp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []providers.Result{
+		{
+			URL:        "https://example.com/login?next=/",
+			StatusCode: "302",
+			Timestamp:  "2023-05-01T10:20:30Z",
+			Meta:       map[string]string{"method": "GET", "burp_mimetype": "HTML"},
+		},
+		{
+			URL:  "https://example.com/app.js",
+			Meta: map[string]string{"method": "GET"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBurp() = %+v, want %+v", got, want)
+	}
+}
@@ -1,6 +1,9 @@
 package providers
 
-import "net/url"
+import (
+	"net/url"
+	"time"
+)
 
 type Filters struct {
 	From              string   `mapstructure:"from"`
@@ -82,3 +85,35 @@ func (f *Filters) GetParameters(forWayback bool) string {
 
 	return params
 }
+
+// Match applies the filters to a result, for providers that can't filter on the server.
+// Results without a status code or mime type only pass the filters that don't require one.
+func (f *Filters) Match(r Result) bool {
+	if f.From != "" || f.To != "" {
+		if t, err := time.Parse(time.RFC3339, r.Timestamp); err == nil {
+			ts := t.UTC().Format("20060102150405")
+			if f.From != "" && ts < f.From {
+				return false
+			}
+			if f.To != "" && len(ts) >= len(f.To) && ts[:len(f.To)] > f.To {
+				return false
+			}
+		}
+	}
+	if len(f.MatchStatusCodes) > 0 && !contains(f.MatchStatusCodes, r.StatusCode) {
+		return false
+	}
+	if len(f.MatchMimeTypes) > 0 && !contains(f.MatchMimeTypes, r.MimeType) {
+		return false
+	}
+	return !contains(f.FilterStatusCodes, r.StatusCode) && !contains(f.FilterMimeTypes, r.MimeType)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
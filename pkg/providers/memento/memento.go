@@ -0,0 +1,134 @@
+package memento
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/httpclient"
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	Name = "memento"
+
+	// DefaultTimeMap is the TimeMap endpoint of the Time Travel aggregator
+	DefaultTimeMap = "https://timetravel.mementoweb.org/timemap/link/"
+
+	// urlPlaceholder is replaced by the requested url in a TimeMap endpoint
+	urlPlaceholder = "{url}"
+
+	// maxTimeMapPages bounds the number of paged TimeMaps followed for a single domain
+	maxTimeMapPages = 1000
+)
+
+var _ providers.Provider = (*Client)(nil)
+
+// Client fetches TimeMaps from a Memento aggregator or from a single archive
+type Client struct {
+	filters providers.Filters
+	config  *providers.Config
+
+	timeMap string
+	prefix  bool
+}
+
+func New(c *providers.Config, filters providers.Filters) *Client {
+	client := &Client{
+		filters: filters,
+		config:  c,
+		timeMap: c.Memento.TimeMap,
+		prefix:  c.Memento.Prefix,
+	}
+	if client.timeMap == "" {
+		client.timeMap = DefaultTimeMap
+	}
+	return client
+}
+
+func (c *Client) Name() string {
+	return Name
+}
+
+// Fetch fetches the TimeMap of domain and sends the urls of its mementos to a channel,
+// following paged TimeMaps. Each url is sent once, with its first memento.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	if c.config.Subdomains(ctx) {
+		logrus.WithField("provider", Name).Warnf("subdomains are not supported, fetching %s only", domain)
+	}
+
+	window := c.filters.DateRange(time.Time{})
+	seen := make(map[string]struct{})
+
+	next := c.formatURL(domain)
+	for page := uint(0); next != "" && page < maxTimeMapPages; page++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			logrus.WithFields(logrus.Fields{"provider": Name, "page": page}).Infof("fetching %s", domain)
+			resp, err := httpclient.MakeRequest(c.config.Client, next, c.config.MaxRetries, c.config.Timeout)
+			if err != nil {
+				return fmt.Errorf("failed to fetch memento timemap(%d): %s", page, err)
+			}
+			tm, err := parseTimeMap(resp)
+			if err != nil {
+				return fmt.Errorf("failed to decode memento timemap(%d): %s", page, err)
+			}
+
+			for _, m := range tm.Mementos {
+				if m.Original == "" || !inWindow(m.Datetime, window) {
+					continue
+				}
+				if _, ok := seen[m.Original]; ok {
+					continue
+				}
+				seen[m.Original] = struct{}{}
+
+				results <- providers.Result{
+					URL:       m.Original,
+					Source:    Name,
+					Timestamp: m.Datetime,
+					Meta: map[string]string{
+						"archive": archiveName(m.URI),
+						"memento": m.URI,
+					},
+				}
+			}
+
+			// guard against TimeMaps that link back to themselves
+			if tm.Next == next {
+				return nil
+			}
+			next = tm.Next
+		}
+	}
+	return nil
+}
+
+// inWindow reports whether an RFC3339 datetime falls within w. Datetimes that
+// can't be parsed are kept.
+func inWindow(datetime string, w providers.TimeWindow) bool {
+	t, err := time.Parse(time.RFC3339, datetime)
+	if err != nil {
+		return true
+	}
+	return !t.Before(w.From) && t.Before(w.To.AddDate(0, 0, 1))
+}
+
+// formatURL returns the TimeMap url for domain, a prefix TimeMap of the whole domain unless
+// prefix is disabled. The requested url replaces the {url} placeholder of the endpoint, or is
+// appended to it.
+func (c *Client) formatURL(domain string) string {
+	uri := "http://" + domain + "/"
+	if c.prefix {
+		uri += "*"
+	}
+
+	if strings.Contains(c.timeMap, urlPlaceholder) {
+		return strings.ReplaceAll(c.timeMap, urlPlaceholder, uri)
+	}
+	return c.timeMap + uri
+}
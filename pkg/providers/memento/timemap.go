@@ -0,0 +1,177 @@
+package memento
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// memento is a single capture listed in a TimeMap
+type memento struct {
+	URI      string
+	Original string
+	Datetime string
+}
+
+// timeMap holds the mementos of a TimeMap and the location of its next page, if any
+type timeMap struct {
+	Original string
+	Mementos []memento
+	Next     string
+}
+
+// jsonTimeMap is the json TimeMap format used by MemGator and the Time Travel service
+type jsonTimeMap struct {
+	OriginalURI string `json:"original_uri"`
+	Mementos    struct {
+		List []struct {
+			Datetime string `json:"datetime"`
+			URI      string `json:"uri"`
+		} `json:"list"`
+	} `json:"mementos"`
+	Pages struct {
+		Next struct {
+			URI string `json:"uri"`
+		} `json:"next"`
+	} `json:"pages"`
+}
+
+// archivedURL matches memento uris that embed the original url after a 14 digit timestamp,
+// e.g. https://web.archive.org/web/20200101000000/https://example.com/
+var archivedURL = regexp.MustCompile(`/\d{14}(?:[a-z]{2}_)?/(https?://.+)$`)
+
+// parseTimeMap parses a link-format (RFC 7089) or json TimeMap.
+func parseTimeMap(body []byte) (timeMap, error) {
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "{") {
+		return parseJSON(body)
+	}
+	return parseLinks(trimmed), nil
+}
+
+func parseJSON(body []byte) (timeMap, error) {
+	var j jsonTimeMap
+	if err := jsoniter.Unmarshal(body, &j); err != nil {
+		return timeMap{}, err
+	}
+
+	tm := timeMap{Original: j.OriginalURI, Next: j.Pages.Next.URI}
+	for _, m := range j.Mementos.List {
+		tm.Mementos = append(tm.Mementos, memento{
+			URI:      m.URI,
+			Original: originalURL(m.URI, j.OriginalURI),
+			Datetime: formatDatetime(m.Datetime),
+		})
+	}
+	return tm, nil
+}
+
+// parseLinks parses a link-format TimeMap, a comma separated list of
+// <uri>; rel="..."; datetime="..." entries.
+func parseLinks(body string) timeMap {
+	var tm timeMap
+	for _, link := range splitOutside(body, ',') {
+		link = strings.TrimSpace(link)
+		start, end := strings.Index(link, "<"), strings.Index(link, ">")
+		if start != 0 || end < 0 {
+			continue
+		}
+		uri := link[1:end]
+
+		params := make(map[string]string)
+		for _, param := range splitOutside(link[end+1:], ';') {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok {
+				continue
+			}
+			params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+
+		rels := strings.Fields(params["rel"])
+		switch {
+		case hasRel(rels, "memento"):
+			tm.Mementos = append(tm.Mementos, memento{URI: uri, Datetime: formatDatetime(params["datetime"])})
+		case hasRel(rels, "original"):
+			tm.Original = uri
+		case hasRel(rels, "next"):
+			tm.Next = uri
+		}
+	}
+
+	for i, m := range tm.Mementos {
+		tm.Mementos[i].Original = originalURL(m.URI, tm.Original)
+	}
+	return tm
+}
+
+// originalURL returns the url a memento is a capture of, taken from the memento uri
+// when the archive embeds it, and fallback otherwise.
+func originalURL(uri, fallback string) string {
+	if m := archivedURL.FindStringSubmatch(uri); m != nil {
+		return m[1]
+	}
+	return fallback
+}
+
+// archiveName returns the host of the archive that holds a memento
+func archiveName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// formatDatetime converts the RFC 1123 datetimes of TimeMaps to RFC3339
+func formatDatetime(datetime string) string {
+	for _, layout := range []string{time.RFC1123, time.RFC3339} {
+		if t, err := time.Parse(layout, datetime); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return datetime
+}
+
+func hasRel(rels []string, rel string) bool {
+	for _, r := range rels {
+		if strings.EqualFold(r, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOutside splits s on sep, ignoring separators inside <> or quotes
+func splitOutside(s string, sep byte) []string {
+	var (
+		parts   []string
+		inAngle bool
+		inQuote bool
+		last    int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '<':
+			if !inQuote {
+				inAngle = true
+			}
+		case '>':
+			if !inQuote {
+				inAngle = false
+			}
+		case '"':
+			if !inAngle {
+				inQuote = !inQuote
+			}
+		case sep:
+			if !inAngle && !inQuote {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
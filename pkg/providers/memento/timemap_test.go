@@ -0,0 +1,84 @@
+package memento
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTimeMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    timeMap
+		wantErr bool
+	}{
+		{
+			name: "links",
+			body: `<https://example.com/>; rel="original",
+<https://web.archive.org/web/timemap/link/https://example.com/>; rel="self"; type="application/link-format",
+<https://web.archive.org/web/20200101000000/https://example.com/>; rel="first memento"; datetime="Wed, 01 Jan 2020 00:00:00 GMT",
+<https://arquivo.pt/wayback/20210101000000/https://example.com/a?x=1,2>; rel="memento"; datetime="Fri, 01 Jan 2021 00:00:00 GMT",
+<https://archive.example/id/1>; rel="last memento"; datetime="Sat, 01 Jan 2022 00:00:00 GMT",
+<https://web.archive.org/web/timemap/link/https://example.com/?page=2>; rel="next"`,
+			want: timeMap{
+				Original: "https://example.com/",
+				Mementos: []memento{
+					{URI: "https://web.archive.org/web/20200101000000/https://example.com/", Original: "https://example.com/", Datetime: "2020-01-01T00:00:00Z"},
+					{URI: "https://arquivo.pt/wayback/20210101000000/https://example.com/a?x=1,2", Original: "https://example.com/a?x=1,2", Datetime: "2021-01-01T00:00:00Z"},
+					{URI: "https://archive.example/id/1", Original: "https://example.com/", Datetime: "2022-01-01T00:00:00Z"},
+				},
+				Next: "https://web.archive.org/web/timemap/link/https://example.com/?page=2",
+			},
+		},
+		{
+			name: "quoted separators",
+			body: `<https://example.com/>; rel="original"; title="a, b; c", <https://web.archive.org/web/20200101000000id_/https://example.com/x>; rel="memento"; datetime="bad date"`,
+			want: timeMap{
+				Original: "https://example.com/",
+				Mementos: []memento{
+					{URI: "https://web.archive.org/web/20200101000000id_/https://example.com/x", Original: "https://example.com/x", Datetime: "bad date"},
+				},
+			},
+		},
+		{
+			name: "json",
+			body: `{
+  "original_uri": "https://example.com/",
+  "mementos": {"list": [
+    {"datetime": "2020-01-01T00:00:00Z", "uri": "https://web.archive.org/web/20200101000000/https://example.com/b"},
+    {"datetime": "2021-01-01T00:00:00Z", "uri": "https://archive.example/id/2"}
+  ]},
+  "pages": {"next": {"uri": "https://timetravel.example/timemap/json/2"}}
+}`,
+			want: timeMap{
+				Original: "https://example.com/",
+				Mementos: []memento{
+					{URI: "https://web.archive.org/web/20200101000000/https://example.com/b", Original: "https://example.com/b", Datetime: "2020-01-01T00:00:00Z"},
+					{URI: "https://archive.example/id/2", Original: "https://example.com/", Datetime: "2021-01-01T00:00:00Z"},
+				},
+				Next: "https://timetravel.example/timemap/json/2",
+			},
+		},
+		{
+			name: "empty",
+			body: "",
+		},
+		{
+			name:    "invalid json",
+			body:    `{"mementos": `,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimeMap([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimeMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTimeMap() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,12 @@ package otx
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bobesa/go-domain-util/domainutil"
 	jsoniter "github.com/json-iterator/go"
@@ -13,28 +18,50 @@ import (
 
 const (
 	Name = "otx"
+
+	// DefaultBaseURL is the location of the OTX API
+	DefaultBaseURL = "https://otx.alienvault.com/"
+
+	// DefaultPageSize is the number of urls requested per page when none is configured
+	DefaultPageSize = 100
+
+	// initial delay before retrying a rate limited request
+	rateLimitBackoff = 10 * time.Second
 )
 
 type Client struct {
 	config *providers.Config
+
+	baseURL  string
+	apiKey   string
+	pageSize uint
 }
 
 var _ providers.Provider = (*Client)(nil)
 
 func New(c *providers.Config) *Client {
-	if c.OTX != "" {
-		setBaseURL(c.OTX)
+	client := &Client{
+		config:   c,
+		baseURL:  providers.BaseURL(c.OTX.Host, DefaultBaseURL),
+		apiKey:   c.OTX.APIKey,
+		pageSize: c.OTX.PageSize,
 	}
-	return &Client{config: c}
+	if client.pageSize == 0 {
+		client.pageSize = DefaultPageSize
+	}
+	return client
 }
 
 type otxResult struct {
 	HasNext    bool `json:"has_next"`
 	ActualSize int  `json:"actual_size"`
-	URLList    []struct {
+	// FullSize is the number of urls OTX knows of, which may be more than it pages through
+	FullSize int `json:"full_size"`
+	URLList  []struct {
 		Domain   string `json:"domain"`
 		URL      string `json:"url"`
 		Hostname string `json:"hostname"`
+		Date     string `json:"date"`
 		HTTPCode int    `json:"httpcode"`
 		PageNum  int    `json:"page_num"`
 		FullSize int    `json:"full_size"`
@@ -42,53 +69,209 @@ type otxResult struct {
 	} `json:"url_list"`
 }
 
+type passiveDNSResult struct {
+	PassiveDNS []struct {
+		Hostname string `json:"hostname"`
+	} `json:"passive_dns"`
+}
+
+// list is a single url_list query and what it returned
+type list struct {
+	// received is the number of urls returned, duplicates included
+	received int
+	full     int
+	// hostnames holds the hostnames of the urls returned
+	hostnames map[string]struct{}
+}
+
+// truncated reports whether OTX stopped paging before returning every url it knows of
+func (l *list) truncated() bool {
+	return l.received < l.full
+}
+
 func (c *Client) Name() string {
 	return Name
 }
 
-func (c *Client) Fetch(ctx context.Context, domain string, results chan string) error {
+// Fetch fetches all urls for a given domain and sends them to a channel. OTX stops paging
+// through a list after a while, so when the list of a domain is truncated, the url lists of
+// its hostnames are fetched one by one. Urls are deduplicated across lists.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	subs := c.config.Subdomains(ctx)
+	category, target := c.target(domain, subs)
+	seen := make(map[string]struct{})
+
+	l, err := c.fetchList(ctx, category, target, results, seen)
+	if err != nil || !l.truncated() || ctx.Err() != nil {
+		return err
+	}
+	if category != "domain" {
+		return fmt.Errorf("incomplete results, otx returned %d of %d urls", l.received, l.full)
+	}
+
+	hostnames := l.hostnames
+	if err := c.passiveDNS(ctx, target, hostnames); err != nil {
+		logrus.WithField("provider", Name).Warnf("%s - failed to fetch passive dns: %v", domain, err)
+	}
+	logrus.WithField("provider", Name).Infof("%s - %d of %d urls returned, fetching %d hostnames", domain, l.received, l.full, len(hostnames))
+
+	var incomplete []string
+	for _, hostname := range sortedHostnames(hostnames) {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !providers.InScope(hostname, domain, subs) {
+			continue
+		}
+		hl, err := c.fetchList(ctx, "hostname", hostname, results, seen)
+		if err != nil {
+			return fmt.Errorf("incomplete results, failed to fetch hostname %s: %v", hostname, err)
+		}
+		if hl.truncated() {
+			incomplete = append(incomplete, hostname)
+		}
+	}
+	if len(incomplete) > 0 {
+		return fmt.Errorf("incomplete results, otx truncated the urls of %s", strings.Join(incomplete, ", "))
+	}
+	return nil
+}
+
+// target returns the indicator category and value queried for domain
+func (c *Client) target(domain string, subs bool) (string, string) {
+	if !domainutil.HasSubdomain(domain) {
+		return "domain", domain
+	}
+	if subs {
+		return "domain", domainutil.Domain(domain)
+	}
+	return "hostname", domain
+}
+
+// fetchList pages through a url list, sending the urls not seen yet
+func (c *Client) fetchList(ctx context.Context, category, target string, results chan providers.Result, seen map[string]struct{}) (*list, error) {
+	l := &list{hostnames: make(map[string]struct{})}
 	for page := uint(1); ; page++ {
 		select {
 		case <-ctx.Done():
-			return nil
+			return l, nil
 		default:
-			logrus.WithFields(logrus.Fields{"provider": Name, "page": page - 1}).Infof("fetching %s", domain)
-			apiURL := c.formatURL(domain, page)
-			resp, err := httpclient.MakeRequest(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout)
+			logrus.WithFields(logrus.Fields{"provider": Name, "page": page - 1}).Infof("fetching %s", target)
+			resp, err := c.request(ctx, c.formatURL(category, target, page))
 			if err != nil {
-				return fmt.Errorf("failed to fetch alienvault(%d): %s", page, err)
+				return l, fmt.Errorf("failed to fetch alienvault(%d): %s", page, err)
 			}
 			var result otxResult
 			if err := jsoniter.Unmarshal(resp, &result); err != nil {
-				return fmt.Errorf("failed to decode otx results for page %d: %s", page, err)
+				return l, fmt.Errorf("failed to decode otx results for page %d: %s", page, err)
+			}
+			if result.FullSize > l.full {
+				l.full = result.FullSize
 			}
 
 			for _, entry := range result.URLList {
-				results <- entry.URL
+				l.received++
+				if entry.Hostname != "" {
+					l.hostnames[strings.ToLower(entry.Hostname)] = struct{}{}
+				}
+				if _, ok := seen[entry.URL]; ok {
+					continue
+				}
+				seen[entry.URL] = struct{}{}
+
+				r := providers.Result{
+					URL:       entry.URL,
+					Source:    Name,
+					Timestamp: formatDate(entry.Date),
+					Meta: map[string]string{
+						"hostname": entry.Hostname,
+						"domain":   entry.Domain,
+					},
+				}
+				if entry.HTTPCode != 0 {
+					r.StatusCode = strconv.Itoa(entry.HTTPCode)
+				}
+				results <- r
 			}
 
 			if !result.HasNext {
-				return nil
+				return l, nil
 			}
 		}
 	}
 }
 
-func (c *Client) formatURL(domain string, page uint) string {
-	category := "hostname"
-	if !domainutil.HasSubdomain(domain) {
-		category = "domain"
+// passiveDNS adds the hostnames OTX resolved under domain to hostnames
+func (c *Client) passiveDNS(ctx context.Context, domain string, hostnames map[string]struct{}) error {
+	resp, err := c.request(ctx, fmt.Sprintf("%sapi/v1/indicators/domain/%s/passive_dns", c.baseURL, domain))
+	if err != nil {
+		return err
+	}
+	var result passiveDNSResult
+	if err := jsoniter.Unmarshal(resp, &result); err != nil {
+		return err
 	}
-	if domainutil.HasSubdomain(domain) && c.config.IncludeSubdomains {
-		domain = domainutil.Domain(domain)
-		category = "domain"
+	for _, entry := range result.PassiveDNS {
+		if entry.Hostname != "" {
+			hostnames[strings.ToLower(entry.Hostname)] = struct{}{}
+		}
 	}
+	return nil
+}
 
-	return fmt.Sprintf("%sapi/v1/indicators/%s/%s/url_list?limit=100&page=%d", _BaseURL, category, domain, page)
+func sortedHostnames(hostnames map[string]struct{}) []string {
+	sorted := make([]string, 0, len(hostnames))
+	for hostname := range hostnames {
+		sorted = append(sorted, hostname)
+	}
+	sort.Strings(sorted)
+	return sorted
 }
 
-var _BaseURL = "https://otx.alienvault.com/"
+// request fetches apiURL, backing off and retrying when OTX rate limits the client
+func (c *Client) request(ctx context.Context, apiURL string) ([]byte, error) {
+	var header httpclient.Header
+	if c.apiKey != "" {
+		header.Key = "X-OTX-API-KEY"
+		header.Value = c.apiKey
+	}
 
-func setBaseURL(baseURL string) {
-	_BaseURL = baseURL
+	backoff := rateLimitBackoff
+	for attempt := uint(0); ; attempt++ {
+		resp, err := httpclient.Do(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout, header)
+		if err == nil {
+			return resp.Body, nil
+		}
+		if !errors.Is(err, httpclient.ErrRateLimited) {
+			return nil, err
+		}
+		if attempt >= c.config.MaxRetries {
+			return nil, fmt.Errorf("rate limited by otx after %d attempts", attempt+1)
+		}
+
+		wait := backoff
+		if after, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(after) * time.Second
+		}
+		logrus.WithField("provider", Name).Infof("rate limited, retrying in %s", wait)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) formatURL(category, target string, page uint) string {
+	return fmt.Sprintf("%sapi/v1/indicators/%s/%s/url_list?limit=%d&page=%d", c.baseURL, category, target, c.pageSize, page)
+}
+
+// formatDate converts the zone-less dates returned by OTX to RFC3339
+func formatDate(date string) string {
+	t, err := time.Parse("2006-01-02T15:04:05", date)
+	if err != nil {
+		return date
+	}
+	return t.Format(time.RFC3339)
 }
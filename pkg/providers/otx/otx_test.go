@@ -0,0 +1,213 @@
+package otx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/valyala/fasthttp"
+)
+
+// newTestClient returns a client for the OTX API served by handler
+func newTestClient(t *testing.T, maxRetries uint, subs bool, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(&providers.Config{
+		Timeout:           5,
+		MaxRetries:        maxRetries,
+		IncludeSubdomains: subs,
+		Client:            &fasthttp.Client{},
+		OTX:               providers.OTX{Host: srv.URL},
+	})
+}
+
+// urlList formats a url_list page holding urls, out of full urls known to OTX
+func urlList(full int, urls ...string) string {
+	entries := make([]string, len(urls))
+	for i, u := range urls {
+		host := strings.SplitN(strings.TrimPrefix(u, "https://"), "/", 2)[0]
+		entries[i] = fmt.Sprintf(`{"url": %q, "hostname": %q, "date": "2020-01-01T00:00:00", "httpcode": 200}`, u, host)
+	}
+	return fmt.Sprintf(`{"has_next": false, "full_size": %d, "url_list": [%s]}`, full, strings.Join(entries, ","))
+}
+
+// fetch runs Fetch and returns the sorted urls it sent
+func fetch(c *Client, domain string) ([]string, error) {
+	results := make(chan providers.Result, 100)
+	err := c.Fetch(context.Background(), domain, results)
+	close(results)
+
+	var urls []string
+	for r := range results {
+		urls = append(urls, r.URL)
+	}
+	sort.Strings(urls)
+	return urls, err
+}
+
+func TestFetchTruncated(t *testing.T) {
+	tests := []struct {
+		name    string
+		domain  string
+		subs    bool
+		lists   map[string]string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "complete",
+			domain: "example.com",
+			lists: map[string]string{
+				"domain/example.com": urlList(1, "https://example.com/a"),
+			},
+			want: []string{"https://example.com/a"},
+		},
+		{
+			name:   "split by hostname",
+			domain: "example.com",
+			subs:   true,
+			lists: map[string]string{
+				"domain/example.com":     urlList(4, "https://example.com/a"),
+				"hostname/example.com":   urlList(2, "https://example.com/a", "https://example.com/b"),
+				"hostname/a.example.com": urlList(1, "https://a.example.com/"),
+				"hostname/b.example.com": urlList(1, "https://b.example.com/"),
+			},
+			want: []string{"https://a.example.com/", "https://b.example.com/", "https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name:   "subdomains out of scope",
+			domain: "example.com",
+			lists: map[string]string{
+				"domain/example.com":   urlList(4, "https://example.com/a"),
+				"hostname/example.com": urlList(2, "https://example.com/a", "https://example.com/b"),
+			},
+			want: []string{"https://example.com/a", "https://example.com/b"},
+		},
+		{
+			name:   "hostname truncated",
+			domain: "example.com",
+			subs:   true,
+			lists: map[string]string{
+				"domain/example.com":     urlList(4, "https://example.com/a"),
+				"hostname/example.com":   urlList(2, "https://example.com/a", "https://example.com/b"),
+				"hostname/a.example.com": urlList(5, "https://a.example.com/"),
+				"hostname/b.example.com": urlList(1, "https://b.example.com/"),
+			},
+			want:    []string{"https://a.example.com/", "https://b.example.com/", "https://example.com/a", "https://example.com/b"},
+			wantErr: "incomplete results, otx truncated the urls of a.example.com",
+		},
+		{
+			name:   "hostname list truncated",
+			domain: "www.example.com",
+			lists: map[string]string{
+				"hostname/www.example.com": urlList(3, "https://www.example.com/"),
+			},
+			want:    []string{"https://www.example.com/"},
+			wantErr: "incomplete results, otx returned 1 of 3 urls",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, 0, tt.subs, func(w http.ResponseWriter, r *http.Request) {
+				path := strings.TrimPrefix(r.URL.Path, "/api/v1/indicators/")
+				if path == "domain/example.com/passive_dns" {
+					w.Write([]byte(`{"passive_dns": [{"hostname": "A.example.com"}, {"hostname": "b.example.com"}, {"hostname": "example.org"}]}`))
+					return
+				}
+				list, ok := tt.lists[strings.TrimSuffix(path, "/url_list")]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write([]byte(list))
+			})
+
+			urls, err := fetch(c, tt.domain)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+			}
+			if strings.Join(urls, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Fetch() sent %v, want %v", urls, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchRateLimited(t *testing.T) {
+	tests := []struct {
+		name       string
+		limited    int32
+		maxRetries uint
+		wantErr    string
+	}{
+		{name: "retried", limited: 2, maxRetries: 2},
+		{name: "out of retries", limited: 2, maxRetries: 1, wantErr: "failed to fetch alienvault(1): rate limited by otx after 2 attempts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			c := newTestClient(t, tt.maxRetries, false, func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= tt.limited {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(urlList(1, "https://example.com/a")))
+			})
+
+			urls, err := fetch(c, "example.com")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Fetch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if strings.Join(urls, ",") != "https://example.com/a" {
+				t.Errorf("Fetch() sent %v", urls)
+			}
+		})
+	}
+}
+
+func TestFetchAPIKey(t *testing.T) {
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("X-OTX-API-KEY")
+		w.Write([]byte(`{"has_next": false, "full_size": 1, "url_list": [
+			{"url": "https://example.com/a", "hostname": "example.com", "domain": "example.com", "date": "2020-01-02T03:04:05", "httpcode": 404}
+		]}`))
+	}))
+	defer srv.Close()
+	c := New(&providers.Config{
+		Timeout: 5,
+		Client:  &fasthttp.Client{},
+		OTX:     providers.OTX{Host: srv.URL, APIKey: "secret"},
+	})
+
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+	if key != "secret" {
+		t.Errorf("Fetch() sent api key %q, want %q", key, "secret")
+	}
+
+	r := <-results
+	if r.StatusCode != "404" || r.Timestamp == "" || r.Meta["hostname"] != "example.com" || r.Meta["domain"] != "example.com" {
+		t.Errorf("Fetch() sent %+v, want the status, date, hostname and domain of the entry", r)
+	}
+}
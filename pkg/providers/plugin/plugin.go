@@ -0,0 +1,218 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/sirupsen/logrus"
+)
+
+// waitDelay is how long a cancelled plugin is given to exit before its pipes are closed
+const waitDelay = 5 * time.Second
+
+var _ providers.Provider = (*Client)(nil)
+
+// Client runs an external executable as a provider. For every domain the plugin is
+// started, sent a request as a line of json on stdin, and streams back results and
+// errors as lines of json on stdout.
+type Client struct {
+	filters providers.Filters
+	config  *providers.Config
+
+	name    string
+	command string
+	args    []string
+	env     []string
+	timeout time.Duration
+}
+
+// request is the line sent to a plugin
+type request struct {
+	Domain  string  `json:"domain"`
+	Subs    bool    `json:"subs"`
+	Filters filters `json:"filters"`
+}
+
+type filters struct {
+	From              string   `json:"from,omitempty"`
+	To                string   `json:"to,omitempty"`
+	MatchStatusCodes  []string `json:"matchstatuscodes,omitempty"`
+	MatchMimeTypes    []string `json:"matchmimetypes,omitempty"`
+	FilterStatusCodes []string `json:"filterstatuscodes,omitempty"`
+	FilterMimeTypes   []string `json:"filtermimetypes,omitempty"`
+}
+
+// message is a line sent by a plugin, either a result or an error
+type message struct {
+	providers.Result
+	Error string `json:"error,omitempty"`
+}
+
+// New returns a Client running the plugin described by p, reporting its results as name.
+func New(name string, p providers.Plugin, config *providers.Config, filters providers.Filters) *Client {
+	return &Client{
+		filters: filters,
+		config:  config,
+		name:    name,
+		command: p.Command,
+		args:    p.Args,
+		env:     p.Env,
+		timeout: time.Duration(p.Timeout) * time.Second,
+	}
+}
+
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Fetch runs the plugin for domain and sends the results it streams back to a channel.
+// Errors reported by the plugin are logged. The plugin is killed once the context is
+// cancelled or its timeout expires.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	logrus.WithFields(logrus.Fields{"provider": c.name}).Infof("fetching %s", domain)
+	cmd := exec.CommandContext(ctx, c.command, c.args...)
+	cmd.Env = append(os.Environ(), c.env...)
+	cmd.WaitDelay = waitDelay
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.logStderr(domain, stderr)
+	}()
+	go func() {
+		defer wg.Done()
+		defer stdin.Close()
+		line, _ := jsoniter.Marshal(c.request(ctx, domain))
+		// a plugin that exits without reading its request is reported by Wait
+		_, _ = stdin.Write(append(line, '\n'))
+	}()
+
+	sent, readErr := c.readResults(ctx, domain, stdout, results)
+	wg.Wait()
+	err = cmd.Wait()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("timed out after %s, stopped after %d urls", c.timeout, sent)
+	case ctx.Err() != nil:
+		return nil
+	case err != nil:
+		return fmt.Errorf("plugin failed after %d urls: %v", sent, err)
+	}
+	return readErr
+}
+
+func (c *Client) request(ctx context.Context, domain string) request {
+	return request{
+		Domain: domain,
+		Subs:   c.config.Subdomains(ctx),
+		Filters: filters{
+			From:              c.filters.From,
+			To:                c.filters.To,
+			MatchStatusCodes:  c.filters.MatchStatusCodes,
+			MatchMimeTypes:    c.filters.MatchMimeTypes,
+			FilterStatusCodes: c.filters.FilterStatusCodes,
+			FilterMimeTypes:   c.filters.FilterMimeTypes,
+		},
+	}
+}
+
+// readResults decodes the lines written by the plugin until it closes stdout or ctx is done.
+// Should a line be too long to read, the rest of the output is discarded so the plugin
+// doesn't block writing to a pipe nobody reads.
+func (c *Client) readResults(ctx context.Context, domain string, r io.Reader, results chan providers.Result) (int, error) {
+	var (
+		sent    int
+		lineErr error
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		var msg message
+		if err := jsoniter.UnmarshalFromString(line, &msg); err != nil {
+			if lineErr == nil {
+				lineErr = fmt.Errorf("invalid line from plugin: %q", truncate(line, 80))
+			}
+			continue
+		}
+		if msg.Error != "" {
+			logrus.WithFields(logrus.Fields{"provider": c.name}).Warnf("%s - %s", domain, msg.Error)
+			continue
+		}
+		if msg.URL == "" {
+			continue
+		}
+
+		result := msg.Result
+		result.Source = c.name
+		select {
+		case <-ctx.Done():
+			return sent, nil
+		case results <- result:
+			sent++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		_, _ = io.Copy(io.Discard, r)
+		return sent, fmt.Errorf("failed to read plugin output, discarded the rest: %v", err)
+	}
+	return sent, lineErr
+}
+
+// logStderr logs every line the plugin writes to stderr. Should a line be too long
+// to read, the rest of stderr is discarded.
+func (c *Client) logStderr(domain string, r io.Reader) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		logrus.WithFields(logrus.Fields{"provider": c.name, "domain": domain}).Info(sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		logrus.WithFields(logrus.Fields{"provider": c.name, "domain": domain}).Warnf("failed to read plugin stderr, discarded the rest: %v", err)
+		_, _ = io.Copy(io.Discard, r)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
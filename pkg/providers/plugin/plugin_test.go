@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// TestMain runs the test binary as a plugin when GAU_TEST_PLUGIN is set
+func TestMain(m *testing.M) {
+	switch os.Getenv("GAU_TEST_PLUGIN") {
+	case "":
+		os.Exit(m.Run())
+	case "echo":
+		var req request
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err := jsoniter.UnmarshalFromString(line, &req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "looking up", req.Domain)
+		fmt.Printf(`{"url": "https://%s/", "statuscode": "200"}`+"\n", req.Domain)
+		fmt.Printf(`{"error": "partial results"}` + "\n")
+		fmt.Printf(`{"url": "https://%s/?subs=%v&from=%s"}`+"\n", req.Domain, req.Subs, req.Filters.From)
+	case "fail":
+		fmt.Println(`{"url": "https://example.com/"}`)
+		os.Exit(3)
+	}
+}
+
+// newTestPlugin returns a client running the test binary as the plugin mode
+func newTestPlugin(mode string, filters providers.Filters) *Client {
+	return New("test", providers.Plugin{
+		Command: os.Args[0],
+		Env:     []string{"GAU_TEST_PLUGIN=" + mode},
+	}, &providers.Config{IncludeSubdomains: true}, filters)
+}
+
+func TestFetch(t *testing.T) {
+	c := newTestPlugin("echo", providers.Filters{From: "202001"})
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+
+	var got []providers.Result
+	for r := range results {
+		got = append(got, r)
+	}
+	want := []providers.Result{
+		{URL: "https://example.com/", StatusCode: "200", Source: "test"},
+		{URL: "https://example.com/?subs=true&from=202001", Source: "test"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() sent %+v, want %+v", got, want)
+	}
+}
+
+func TestFetchFailure(t *testing.T) {
+	c := newTestPlugin("fail", providers.Filters{})
+	results := make(chan providers.Result, 10)
+	err := c.Fetch(context.Background(), "example.com", results)
+	if err == nil || !strings.HasPrefix(err.Error(), "plugin failed after 1 urls") {
+		t.Errorf("Fetch() error = %v, want the exit status of the plugin", err)
+	}
+}
+
+func TestReadResults(t *testing.T) {
+	c := &Client{name: "test"}
+	output := strings.Join([]string{
+		`{"url": "https://example.com/a", "meta": {"tool": "x"}}`,
+		``,
+		`not json`,
+		`{"error": "lookup failed"}`,
+		`{"statuscode": "200"}`,
+		`{"url": "https://example.com/b"}`,
+	}, "\n")
+
+	results := make(chan providers.Result, 10)
+	sent, err := c.readResults(context.Background(), "example.com", strings.NewReader(output), results)
+	close(results)
+	if sent != 2 {
+		t.Errorf("readResults() sent %d results, want 2", sent)
+	}
+	if err == nil || err.Error() != `invalid line from plugin: "not json"` {
+		t.Errorf("readResults() error = %v, want the invalid line", err)
+	}
+	if r := <-results; r.URL != "https://example.com/a" || r.Source != "test" || r.Meta["tool"] != "x" {
+		t.Errorf("readResults() sent %+v", r)
+	}
+}
@@ -2,6 +2,10 @@ package providers
 
 import (
 	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/valyala/fasthttp"
@@ -11,15 +15,142 @@ const Version = `2.2.4`
 
 // Provider is a generic interface for all archive fetchers
 type Provider interface {
-	Fetch(ctx context.Context, domain string, results chan string) error
+	Fetch(ctx context.Context, domain string, results chan Result) error
 	Name() string
 }
 
+// ErrNotPaginated is returned by a Paginator that can't split a domain into pages
+// with its current configuration. The domain should be fetched with Fetch instead.
+var ErrNotPaginated = errors.New("provider is not paginated")
+
+// Paginator is implemented by providers that can split the results for a domain
+// into pages that can be fetched independently of one another.
+type Paginator interface {
+	Provider
+	// Pages returns the number of pages available for domain. The context carries a
+	// PageState, fresh for every call, that Pages may keep its pagination in.
+	Pages(ctx context.Context, domain string) (uint, error)
+	// FetchPage fetches a single page of results for domain, with a context carrying
+	// the PageState of the Pages call the page belongs to
+	FetchPage(ctx context.Context, domain string, page uint, results chan Result) error
+	// MaxConcurrency returns how many pages may be fetched at once across all domains
+	MaxConcurrency() uint
+}
+
 type URLScan struct {
+	Host     string
+	APIKey   string
+	MaxWait  uint
+	Query    string
+	PageSize uint
+}
+
+type OTX struct {
+	Host     string
+	APIKey   string
+	PageSize uint
+}
+
+type Wayback struct {
+	Host        string
+	Collapse    string
+	Pagination  string
+	Concurrency uint
+}
+
+type CommonCrawl struct {
+	Concurrency uint
+	Collections []string
+	CollInfo    string
+	CacheTTL    uint
+}
+
+// CDX describes a CDX server instance
+type CDX struct {
+	URL         string
+	Headers     map[string]string
+	Fields      map[string]string
+	Collapse    string
+	Pagination  string
+	Concurrency uint
+}
+
+// Memento describes the TimeMap endpoint of a Memento aggregator or archive
+type Memento struct {
+	TimeMap string
+	Prefix  bool
+}
+
+type VirusTotal struct {
+	Host      string
+	APIKeys   []string
+	RateLimit uint
+}
+
+type URLhaus struct {
 	Host   string
 	APIKey string
 }
 
+// CCIndex lists directories of downloaded Common Crawl index shards
+type CCIndex struct {
+	Dirs []string
+}
+
+// WARC lists the WARC and WACZ files, directories or globs to read
+type WARC struct {
+	Paths []string
+}
+
+// File lists globs of url lists, HAR files and Burp Suite exports to read
+type File struct {
+	Paths []string
+}
+
+// Plugin describes an external executable used as a provider
+type Plugin struct {
+	Command string
+	Args    []string
+	// Env holds KEY=value pairs added to the plugin's environment
+	Env     []string
+	Timeout uint
+}
+
+// CrtSh configures the subdomain expansion stage
+type CrtSh struct {
+	Enabled bool
+	Host    string
+}
+
+// Category holds the glob patterns a result is matched against to be tagged with a category.
+// A result belongs to the category as soon as one pattern matches.
+type Category struct {
+	Extensions []string
+	Paths      []string
+	MimeTypes  []string
+	Params     []string
+}
+
+// Classify configures the url classifier stage
+type Classify struct {
+	Enabled bool
+	// Match keeps the results tagged with one of these categories only
+	Match []string
+	// Dir is the directory a list of urls per category is written to
+	Dir   string
+	Rules map[string]Category
+}
+
+// Patterns configures the pattern matching stage
+type Patterns struct {
+	// Match holds the names of the packs to run, all runs every pack
+	Match []string
+	// Only keeps the results matching a pack only
+	Only bool
+	// Dirs are directories of packs, replacing the built-in packs of the same name
+	Dirs []string
+}
+
 type Config struct {
 	Threads           uint
 	Timeout           uint
@@ -31,6 +162,107 @@ type Config struct {
 	Blacklist         mapset.Set[string]
 	Output            string
 	JSON              bool
+	OutputMode        string
+	Origins           bool
+	HostCounts        bool
+	ParamsScope       string
+	ParamsSources     []string
+	ValuesMax         uint
+	ValuesDir         string
+	PathTypes         []string
+	PathsMinCount     uint
+	PathsMaxDepth     uint
+	PathsMerge        bool
 	URLScan           URLScan
-	OTX               string
+	Wayback           Wayback
+	CommonCrawl       CommonCrawl
+	CDX               map[string]CDX
+	Ordered           bool
+	OTX               OTX
+	Memento           Memento
+	VirusTotal        VirusTotal
+	URLhaus           URLhaus
+	CCIndex           CCIndex
+	WARC              WARC
+	File              File
+	Plugins           map[string]Plugin
+	CrtSh             CrtSh
+	Classify          Classify
+	Patterns          Patterns
+}
+
+type exactHostKey struct{}
+
+// WithExactHost returns a context for queries that cover the queried host alone,
+// whatever the subdomain setting.
+func WithExactHost(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exactHostKey{}, true)
+}
+
+// Subdomains reports whether a query made with ctx should include subdomains
+func (c *Config) Subdomains(ctx context.Context) bool {
+	exact, _ := ctx.Value(exactHostKey{}).(bool)
+	return c.IncludeSubdomains && !exact
+}
+
+type pageStateKey struct{}
+
+// PageState holds whatever a Paginator needs to fetch the pages returned by one Pages call.
+// It is discarded along with the context once the pages were fetched.
+type PageState struct {
+	mu    sync.Mutex
+	value any
+}
+
+// Store sets the state
+func (s *PageState) Store(v any) {
+	s.mu.Lock()
+	s.value = v
+	s.mu.Unlock()
+}
+
+// Load returns the state, nil if none was stored
+func (s *PageState) Load() any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
+
+// WithPageState returns a context carrying a new PageState, used for a single Pages call
+// and the FetchPage calls of its pages.
+func WithPageState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pageStateKey{}, &PageState{})
+}
+
+// PageStateFrom returns the PageState carried by ctx, nil if there is none
+func PageStateFrom(ctx context.Context) *PageState {
+	s, _ := ctx.Value(pageStateKey{}).(*PageState)
+	return s
+}
+
+// BaseURL returns host with a trailing slash, or fallback if host is empty.
+func BaseURL(host, fallback string) string {
+	if host == "" {
+		return fallback
+	}
+	return strings.TrimSuffix(host, "/") + "/"
+}
+
+// InScope reports whether the host of rawURL is domain, or one of its subdomains if subs is set.
+// Hosts are compared case-insensitively. URLs without a scheme are accepted, so bare hostnames
+// and the scheme-less lines of url lists can be checked too.
+func InScope(rawURL, domain string, subs bool) bool {
+	domain = strings.ToLower(domain)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == domain {
+		return true
+	}
+	return subs && strings.HasSuffix(host, "."+domain)
 }
@@ -0,0 +1,27 @@
+package providers
+
+import "time"
+
+// Result is a url found by a provider along with whatever metadata
+// the provider's source returned for it.
+type Result struct {
+	URL        string            `json:"url"`
+	Source     string            `json:"source,omitempty"`
+	Timestamp  string            `json:"timestamp,omitempty"`
+	StatusCode string            `json:"statuscode,omitempty"`
+	MimeType   string            `json:"mimetype,omitempty"`
+	Digest     string            `json:"digest,omitempty"`
+	Length     string            `json:"length,omitempty"`
+	Meta       map[string]string `json:"meta,omitempty"`
+	Categories []string          `json:"categories,omitempty"`
+}
+
+// CDXTime converts a CDX timestamp (yyyyMMddhhmmss) to RFC3339.
+// The timestamp is returned unchanged should it fail to parse.
+func CDXTime(ts string) string {
+	t, err := time.Parse("20060102150405", ts)
+	if err != nil {
+		return ts
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"context"
+	"time"
+)
+
+// TimeWindow is an inclusive range of days used to split a query
+type TimeWindow struct {
+	From time.Time
+	To   time.Time
+}
+
+func (w TimeWindow) String() string {
+	return w.From.Format("2006-01-02") + ".." + w.To.Format("2006-01-02")
+}
+
+// DateRange returns the window covered by the from/to filters. Unset bounds
+// default to start and to today.
+func (f *Filters) DateRange(start time.Time) TimeWindow {
+	w := TimeWindow{From: start, To: time.Now().UTC().Truncate(24 * time.Hour)}
+	if t, err := time.Parse("200601", f.From); err == nil {
+		w.From = t
+	}
+	if t, err := time.Parse("200601", f.To); err == nil {
+		// include the whole month
+		w.To = t.AddDate(0, 1, -1)
+	}
+	return w
+}
+
+// SplitWindow recursively halves w while count reports at least limit results for
+// a window, so that every returned window can be fetched without hitting the limit.
+// Windows of a single day are never split further.
+func SplitWindow(ctx context.Context, w TimeWindow, limit int, count func(TimeWindow) (int, error)) ([]TimeWindow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	total, err := count(w)
+	if err != nil {
+		return nil, err
+	}
+	days := int(w.To.Sub(w.From).Hours() / 24)
+	if total < limit || days < 1 {
+		if total == 0 {
+			return nil, nil
+		}
+		return []TimeWindow{w}, nil
+	}
+
+	mid := w.From.AddDate(0, 0, days/2)
+	left, err := SplitWindow(ctx, TimeWindow{From: w.From, To: mid}, limit, count)
+	if err != nil {
+		return nil, err
+	}
+	right, err := SplitWindow(ctx, TimeWindow{From: mid.AddDate(0, 0, 1), To: w.To}, limit, count)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
@@ -0,0 +1,113 @@
+package urlhaus
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/httpclient"
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	Name = "urlhaus"
+
+	// DefaultBaseURL is the location of the URLhaus API
+	DefaultBaseURL = "https://urlhaus-api.abuse.ch/"
+)
+
+var _ providers.Provider = (*Client)(nil)
+
+type Client struct {
+	config *providers.Config
+
+	baseURL string
+	apiKey  string
+}
+
+func New(c *providers.Config) *Client {
+	return &Client{
+		config:  c,
+		baseURL: providers.BaseURL(c.URLhaus.Host, DefaultBaseURL),
+		apiKey:  c.URLhaus.APIKey,
+	}
+}
+
+type hostResponse struct {
+	QueryStatus string `json:"query_status"`
+	URLs        []struct {
+		URL       string   `json:"url"`
+		Status    string   `json:"url_status"`
+		DateAdded string   `json:"date_added"`
+		Threat    string   `json:"threat"`
+		Tags      []string `json:"tags"`
+		Reference string   `json:"urlhaus_reference"`
+	} `json:"urls"`
+}
+
+func (c *Client) Name() string {
+	return Name
+}
+
+// Fetch looks up the urls URLhaus has recorded for a host and sends them to a channel.
+// The host lookup matches the domain exactly, subdomains are not included.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{"provider": Name}).Infof("fetching %s", domain)
+	var header httpclient.Header
+	if c.apiKey != "" {
+		header.Key = "Auth-Key"
+		header.Value = c.apiKey
+	}
+
+	resp, err := httpclient.PostForm(c.config.Client, c.baseURL+"v1/host/", url.Values{"host": {domain}}, c.config.MaxRetries, c.config.Timeout, header)
+	if err != nil {
+		return fmt.Errorf("failed to fetch urlhaus: %s", err)
+	}
+
+	var result hostResponse
+	if err := jsoniter.Unmarshal(resp, &result); err != nil {
+		return fmt.Errorf("failed to decode urlhaus result: %s", err)
+	}
+	switch result.QueryStatus {
+	case "ok":
+	case "no_results":
+		return nil
+	default:
+		return fmt.Errorf("received an error from urlhaus: %s", result.QueryStatus)
+	}
+
+	for _, entry := range result.URLs {
+		meta := map[string]string{
+			"status":    entry.Status,
+			"threat":    entry.Threat,
+			"reference": entry.Reference,
+		}
+		if len(entry.Tags) > 0 {
+			meta["tags"] = strings.Join(entry.Tags, ",")
+		}
+		results <- providers.Result{
+			URL:       entry.URL,
+			Source:    Name,
+			Timestamp: formatDate(entry.DateAdded),
+			Meta:      meta,
+		}
+	}
+	return nil
+}
+
+// formatDate converts the dates returned by URLhaus to RFC3339
+func formatDate(date string) string {
+	t, err := time.Parse("2006-01-02 15:04:05 MST", date)
+	if err != nil {
+		return date
+	}
+	return t.UTC().Format(time.RFC3339)
+}
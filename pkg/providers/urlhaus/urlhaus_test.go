@@ -0,0 +1,89 @@
+package urlhaus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/valyala/fasthttp"
+)
+
+// newTestClient returns a client for the URLhaus API served by handler
+func newTestClient(t *testing.T, apiKey string, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(&providers.Config{
+		Timeout: 5,
+		Client:  &fasthttp.Client{},
+		URLhaus: providers.URLhaus{Host: srv.URL, APIKey: apiKey},
+	})
+}
+
+func TestFetch(t *testing.T) {
+	var host, key string
+	c := newTestClient(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/host/" {
+			http.NotFound(w, r)
+			return
+		}
+		host, key = r.PostFormValue("host"), r.Header.Get("Auth-Key")
+		w.Write([]byte(`{"query_status": "ok", "urls": [{
+			"url": "http://example.com/bin.sh",
+			"url_status": "offline",
+			"date_added": "2023-05-01 10:20:30 UTC",
+			"threat": "malware_download",
+			"tags": ["elf", "mirai"],
+			"urlhaus_reference": "https://urlhaus.abuse.ch/url/1/"
+		}]}`))
+	})
+
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+	if host != "example.com" || key != "secret" {
+		t.Errorf("Fetch() looked up host %q with key %q", host, key)
+	}
+
+	want := providers.Result{
+		URL:       "http://example.com/bin.sh",
+		Source:    Name,
+		Timestamp: "2023-05-01T10:20:30Z",
+		Meta: map[string]string{
+			"status":    "offline",
+			"threat":    "malware_download",
+			"reference": "https://urlhaus.abuse.ch/url/1/",
+			"tags":      "elf,mirai",
+		},
+	}
+	if got := <-results; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() sent %+v, want %+v", got, want)
+	}
+}
+
+func TestFetchQueryStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{status: "no_results"},
+		{status: "invalid_host", wantErr: true},
+	}
+	for _, tt := range tests {
+		c := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"query_status": "` + tt.status + `"}`))
+		})
+		results := make(chan providers.Result, 10)
+		if err := c.Fetch(context.Background(), "example.com", results); (err != nil) != tt.wantErr {
+			t.Errorf("Fetch() with query status %s: error = %v, want error %v", tt.status, err, tt.wantErr)
+		}
+		if len(results) != 0 {
+			t.Errorf("Fetch() with query status %s sent %d results", tt.status, len(results))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package urlscan
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxWait is the longest the client waits for a rate limit to reset when none is configured
+const DefaultMaxWait = 2 * time.Minute
+
+// minLimitedWait is the shortest wait after a rate limited response
+const minLimitedWait = time.Second
+
+// rateLimit holds the state of urlscan's rate limit as reported in the X-Rate-Limit-* headers
+type rateLimit struct {
+	Remaining int
+	Reset     time.Duration
+	Known     bool
+}
+
+// parseRateLimit reads the rate limit headers of a response. The reset time is taken
+// from X-Rate-Limit-Reset-After, X-Rate-Limit-Reset or Retry-After, in that order.
+func parseRateLimit(header http.Header) rateLimit {
+	var rl rateLimit
+	if header == nil {
+		return rl
+	}
+
+	if remaining, err := strconv.Atoi(header.Get("X-Rate-Limit-Remaining")); err == nil {
+		rl.Remaining = remaining
+		rl.Known = true
+	}
+
+	if after, err := strconv.ParseFloat(header.Get("X-Rate-Limit-Reset-After"), 64); err == nil {
+		rl.Reset = time.Duration(after * float64(time.Second))
+	} else if reset, err := time.Parse(time.RFC3339, header.Get("X-Rate-Limit-Reset")); err == nil {
+		rl.Reset = time.Until(reset)
+	} else if after, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		rl.Reset = time.Duration(after) * time.Second
+	}
+
+	if rl.Reset < 0 {
+		rl.Reset = 0
+	}
+	return rl
+}
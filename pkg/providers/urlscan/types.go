@@ -4,19 +4,25 @@ import (
 	"strings"
 )
 
-var _BaseURL = "https://urlscan.io/"
-
 type apiResponse struct {
 	Status  int            `json:"status"`
+	Total   int            `json:"total"`
 	Results []searchResult `json:"results"`
 	HasMore bool           `json:"has_more"`
 }
 
 type searchResult struct {
+	ID   string `json:"_id"`
 	Page archivedPage
+	Task scanTask      `json:"task"`
 	Sort []interface{} `json:"sort"`
 }
 
+type scanTask struct {
+	UUID string `json:"uuid"`
+	Time string `json:"time"`
+}
+
 type archivedPage struct {
 	Domain   string `json:"domain"`
 	MimeType string `json:"mimeType"`
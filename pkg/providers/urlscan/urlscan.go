@@ -3,8 +3,11 @@ package urlscan
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/lc/gau/v2/pkg/httpclient"
@@ -14,60 +17,120 @@ import (
 
 const (
 	Name = "urlscan"
+
+	// DefaultBaseURL is the location of the urlscan API
+	DefaultBaseURL = "https://urlscan.io/"
+
+	// resultCap is the number of results urlscan paginates through for a single query
+	resultCap = 10000
+
+	// DefaultPageSize is the number of results requested per page without an API key
+	DefaultPageSize = 100
+	// DefaultAPIKeyPageSize is the number of results requested per page with an API key
+	DefaultAPIKeyPageSize = 1000
+	// MaxPageSize is the largest page urlscan returns
+	MaxPageSize = 10000
 )
 
+// epoch is the earliest date queried when no --from filter is set
+var epoch = time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 type Client struct {
-	config *providers.Config
+	filters providers.Filters
+	config  *providers.Config
+
+	baseURL  string
+	apiKey   string
+	query    string
+	pageSize int
+	maxWait  time.Duration
 }
 
-func New(c *providers.Config) *Client {
-	if c.URLScan.Host != "" {
-		setBaseURL(c.URLScan.Host)
+func New(c *providers.Config, filters providers.Filters) *Client {
+	client := &Client{
+		filters:  filters,
+		config:   c,
+		baseURL:  providers.BaseURL(c.URLScan.Host, DefaultBaseURL),
+		apiKey:   c.URLScan.APIKey,
+		query:    c.URLScan.Query,
+		pageSize: int(c.URLScan.PageSize),
+		maxWait:  time.Duration(c.URLScan.MaxWait) * time.Second,
+	}
+
+	// raise the page size by default when an API key is set
+	if client.pageSize == 0 {
+		client.pageSize = DefaultPageSize
+		if client.apiKey != "" {
+			client.pageSize = DefaultAPIKeyPageSize
+		}
+	}
+	if client.pageSize > MaxPageSize {
+		client.pageSize = MaxPageSize
 	}
 
-	return &Client{config: c}
+	if client.maxWait == 0 {
+		client.maxWait = DefaultMaxWait
+	}
+	return client
 }
 
 func (c *Client) Name() string {
 	return Name
 }
 
-func (c *Client) Fetch(ctx context.Context, domain string, results chan string) error {
-	var searchAfter string
-	var header httpclient.Header
+// Fetch fetches all urls for a given domain and sends them to a channel. The date range
+// is split into windows small enough for urlscan to paginate through all of their results,
+// and the results of the windows are deduplicated.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	windows, err := providers.SplitWindow(ctx, c.filters.DateRange(epoch), resultCap, func(w providers.TimeWindow) (int, error) {
+		return c.count(ctx, domain, w)
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	if len(windows) > 1 {
+		ranges := make([]string, len(windows))
+		for i, w := range windows {
+			ranges[i] = w.String()
+		}
+		logrus.WithFields(logrus.Fields{"provider": Name}).Infof("split %s into %d windows: %s", domain, len(windows), strings.Join(ranges, ", "))
+	}
 
-	if c.config.URLScan.APIKey != "" {
-		header.Key = "API-Key"
-		header.Value = c.config.URLScan.APIKey
+	seen := make(map[string]struct{})
+	var sent int
+	for _, w := range windows {
+		if err := c.fetchWindow(ctx, domain, w, results, seen, &sent); err != nil {
+			return fmt.Errorf("incomplete results, stopped after %d urls: %v", sent, err)
+		}
 	}
+	return nil
+}
 
+// fetchWindow pages through the results of a single time window
+func (c *Client) fetchWindow(ctx context.Context, domain string, w providers.TimeWindow, results chan providers.Result, seen map[string]struct{}, sent *int) error {
+	var searchAfter string
 	for page := uint(0); ; page++ {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
-			logrus.WithFields(logrus.Fields{"provider": Name, "page": page}).Infof("fetching %s", domain)
-			apiURL := c.formatURL(domain, searchAfter)
-			resp, err := httpclient.MakeRequest(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout, header)
+			logrus.WithFields(logrus.Fields{"provider": Name, "page": page, "window": w.String()}).Infof("fetching %s", domain)
+			result, rl, err := c.search(ctx, c.formatURL(domain, w, searchAfter, c.pageSize))
 			if err != nil {
-				return fmt.Errorf("failed to fetch urlscan: %s", err)
-			}
-			var result apiResponse
-			decoder := jsoniter.NewDecoder(bytes.NewReader(resp))
-			decoder.UseNumber()
-			if err = decoder.Decode(&result); err != nil {
-				return fmt.Errorf("failed to decode urlscan result:  %s", err)
-			}
-			// rate limited
-			if result.Status == 429 {
-				logrus.WithField("provider", "urlscan").Warnf("urlscan responded with 429, probably being rate limited")
-				return nil
+				return err
 			}
 
 			total := len(result.Results)
 			for i, res := range result.Results {
-				if res.Page.Domain == domain || (c.config.IncludeSubdomains && strings.HasSuffix(res.Page.Domain, domain)) {
-					results <- res.Page.URL
+				if providers.InScope(res.Page.Domain, domain, c.config.Subdomains(ctx)) {
+					if _, ok := seen[res.Page.URL]; !ok {
+						seen[res.Page.URL] = struct{}{}
+						results <- c.result(res)
+						*sent++
+					}
 				}
 
 				if i == total-1 {
@@ -82,18 +145,126 @@ func (c *Client) Fetch(ctx context.Context, domain string, results chan string)
 			if !result.HasMore {
 				return nil
 			}
+
+			// out of requests, wait for the reset before asking for the next page
+			if rl.Known && rl.Remaining == 0 {
+				if _, err := c.waitForReset(ctx, rl, false, 0); err != nil {
+					return err
+				}
+			}
 		}
 	}
 }
 
-func (c *Client) formatURL(domain string, after string) string {
-	if after != "" {
-		after = "&search_after=" + after
+// result maps a search result, linking it to the scan it was found in
+func (c *Client) result(res searchResult) providers.Result {
+	r := providers.Result{
+		URL:        res.Page.URL,
+		Source:     Name,
+		Timestamp:  res.Task.Time,
+		StatusCode: res.Page.Status,
+		MimeType:   res.Page.MimeType,
+	}
+
+	uuid := res.Task.UUID
+	if uuid == "" {
+		uuid = res.ID
 	}
+	if uuid != "" {
+		r.Meta = map[string]string{
+			"scan":     uuid,
+			"scan_url": c.baseURL + "result/" + uuid + "/",
+		}
+	}
+	return r
+}
+
+// count returns the number of results urlscan reports for domain within w
+func (c *Client) count(ctx context.Context, domain string, w providers.TimeWindow) (int, error) {
+	result, _, err := c.search(ctx, c.formatURL(domain, w, "", 1))
+	if err != nil {
+		return 0, err
+	}
+	return result.Total, nil
+}
+
+// search requests apiURL, waiting for the rate limit to reset and retrying while rate limited.
+// It gives up once the waits for a single request would add up to more than the maximum wait.
+func (c *Client) search(ctx context.Context, apiURL string) (apiResponse, rateLimit, error) {
+	var header httpclient.Header
+	if c.apiKey != "" {
+		header.Key = "API-Key"
+		header.Value = c.apiKey
+	}
+
+	var waited time.Duration
+	for {
+		var result apiResponse
+		resp, err := httpclient.Do(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout, header)
+		if err != nil && !errors.Is(err, httpclient.ErrRateLimited) {
+			return result, rateLimit{}, fmt.Errorf("failed to fetch urlscan: %s", err)
+		}
+		rl := parseRateLimit(resp.Header)
+
+		if err == nil {
+			decoder := jsoniter.NewDecoder(bytes.NewReader(resp.Body))
+			decoder.UseNumber()
+			if err = decoder.Decode(&result); err != nil {
+				return result, rl, fmt.Errorf("failed to decode urlscan result:  %s", err)
+			}
+		}
 
-	return fmt.Sprintf(_BaseURL+"api/v1/search/?q=domain:%s&size=100", domain) + after
+		// rate limited, wait for the reset and retry
+		if resp.StatusCode == 429 || result.Status == 429 {
+			wait, err := c.waitForReset(ctx, rl, true, waited)
+			if err != nil {
+				return result, rl, err
+			}
+			waited += wait
+			continue
+		}
+		return result, rl, nil
+	}
 }
 
-func setBaseURL(baseURL string) {
-	_BaseURL = baseURL
+// waitForReset sleeps until the rate limit resets and returns how long it waited. It returns
+// an error if the reset, added to the time already waited, is further away than the configured
+// maximum wait. When limited is true, at least a second is waited for, and a full minute when
+// the reset time is unknown.
+func (c *Client) waitForReset(ctx context.Context, rl rateLimit, limited bool, waited time.Duration) (time.Duration, error) {
+	wait := rl.Reset
+	if limited {
+		if wait == 0 {
+			wait = time.Minute
+		} else if wait < minLimitedWait {
+			wait = minLimitedWait
+		}
+	}
+	if waited+wait > c.maxWait {
+		if waited > 0 {
+			return 0, fmt.Errorf("still rate limited after waiting %s, reset in %s exceeds the maximum wait of %s", waited.Round(time.Second), wait.Round(time.Second), c.maxWait)
+		}
+		return 0, fmt.Errorf("rate limited, reset in %s exceeds the maximum wait of %s", wait.Round(time.Second), c.maxWait)
+	}
+
+	logrus.WithField("provider", Name).Infof("rate limited, waiting %s for reset", wait.Round(time.Second))
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-time.After(wait):
+		return wait, nil
+	}
+}
+
+func (c *Client) formatURL(domain string, w providers.TimeWindow, after string, size int) string {
+	if after != "" {
+		after = "&search_after=" + after
+	}
+
+	query := "domain:" + domain
+	if c.query != "" {
+		query += " AND (" + c.query + ")"
+	}
+	query += fmt.Sprintf(" AND date:[%s TO %s]", w.From.Format("2006-01-02"), w.To.Format("2006-01-02"))
+	return fmt.Sprintf("%sapi/v1/search/?q=%s&size=%d", c.baseURL, url.QueryEscape(query), size) + after
 }
@@ -0,0 +1,150 @@
+package urlscan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/valyala/fasthttp"
+)
+
+// newTestClient returns a client for the urlscan API served by handler
+func newTestClient(t *testing.T, maxWait uint, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(&providers.Config{
+		Timeout:           5,
+		Client:            &fasthttp.Client{},
+		URLScan:           providers.URLScan{Host: srv.URL, MaxWait: maxWait},
+		IncludeSubdomains: true,
+	}, providers.Filters{})
+}
+
+// rateLimited answers with a 429 and a reset in after seconds
+func rateLimited(w http.ResponseWriter, after string) {
+	w.Header().Set("X-Rate-Limit-Remaining", "0")
+	w.Header().Set("X-Rate-Limit-Reset-After", after)
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte(`{"status": 429}`))
+}
+
+func TestSearchRateLimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		limited int32
+		after   string
+		maxWait uint
+		wantErr string
+	}{
+		{name: "retried after reset", limited: 1, after: "0.01", maxWait: 1},
+		{name: "reset beyond max wait", limited: -1, after: "5", maxWait: 1, wantErr: "rate limited, reset in 5s exceeds the maximum wait of 1s"},
+		{name: "waits add up", limited: -1, after: "1", maxWait: 1, wantErr: "still rate limited after waiting 1s, reset in 1s exceeds the maximum wait of 1s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			c := newTestClient(t, tt.maxWait, func(w http.ResponseWriter, r *http.Request) {
+				if n := atomic.AddInt32(&requests, 1); tt.limited < 0 || n <= tt.limited {
+					rateLimited(w, tt.after)
+					return
+				}
+				w.Write([]byte(`{"total": 7, "results": [], "has_more": false}`))
+			})
+
+			result, _, err := c.search(context.Background(), c.baseURL+"api/v1/search/?q=domain:example.com")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("search() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("search() error = %v", err)
+			}
+			if result.Total != 7 || atomic.LoadInt32(&requests) != tt.limited+1 {
+				t.Errorf("search() total = %d after %d requests, want 7 after %d", result.Total, requests, tt.limited+1)
+			}
+		})
+	}
+}
+
+func TestSearchCanceled(t *testing.T) {
+	c := newTestClient(t, 60, func(w http.ResponseWriter, r *http.Request) {
+		rateLimited(w, "30")
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, _, err := c.search(ctx, c.baseURL+"api/v1/search/"); err == nil {
+		t.Fatal("search() returned no error once canceled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("search() returned %s after cancellation", elapsed)
+	}
+}
+
+func TestFetchPartialResults(t *testing.T) {
+	c := newTestClient(t, 1, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Get("size") == "1":
+			w.Write([]byte(`{"total": 2, "results": [], "has_more": true}`))
+		case r.URL.Query().Get("search_after") == "":
+			w.Write([]byte(`{"total": 2, "has_more": true, "results": [
+				{"_id": "1", "page": {"domain": "example.com", "url": "https://example.com/a"}, "sort": [1, "1"]}
+			]}`))
+		default:
+			rateLimited(w, "5")
+		}
+	})
+
+	results := make(chan providers.Result, 10)
+	err := c.Fetch(context.Background(), "example.com", results)
+	close(results)
+
+	want := "incomplete results, stopped after 1 urls: rate limited, reset in 5s exceeds the maximum wait of 1s"
+	if err == nil || err.Error() != want {
+		t.Fatalf("Fetch() error = %v, want %q", err, want)
+	}
+	var urls []string
+	for r := range results {
+		urls = append(urls, r.URL)
+	}
+	if strings.Join(urls, ",") != "https://example.com/a" {
+		t.Errorf("Fetch() sent %v, want the urls of the first page", urls)
+	}
+}
+
+func TestFetchScope(t *testing.T) {
+	c := newTestClient(t, 1, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("size") == "1" {
+			w.Write([]byte(`{"total": 4, "results": [], "has_more": false}`))
+			return
+		}
+		w.Write([]byte(`{"total": 4, "has_more": false, "results": [
+			{"_id": "1", "page": {"domain": "example.com", "url": "https://example.com/a"}},
+			{"_id": "2", "page": {"domain": "WWW.Example.com", "url": "https://www.example.com/b"}},
+			{"_id": "3", "page": {"domain": "notexample.com", "url": "https://notexample.com/c"}},
+			{"_id": "4", "page": {"domain": "example.com.evil.net", "url": "https://example.com.evil.net/d"}}
+		]}`))
+	})
+
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+	var urls []string
+	for r := range results {
+		urls = append(urls, r.URL)
+	}
+	if strings.Join(urls, ",") != "https://example.com/a,https://www.example.com/b" {
+		t.Errorf("Fetch() sent %v, want the urls of example.com and its subdomains", urls)
+	}
+}
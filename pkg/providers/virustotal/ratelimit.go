@@ -0,0 +1,70 @@
+package virustotal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRequestsPerMinute is the request quota of a free VirusTotal API key
+const DefaultRequestsPerMinute = 4
+
+// keyLimiter hands out API keys, spacing the requests made with each key
+// so that none of them exceeds its per-minute quota.
+type keyLimiter struct {
+	mu       sync.Mutex
+	keys     []string
+	next     []time.Time
+	interval time.Duration
+}
+
+func newKeyLimiter(keys []string, perMinute uint) *keyLimiter {
+	if perMinute == 0 {
+		perMinute = DefaultRequestsPerMinute
+	}
+	return &keyLimiter{
+		keys:     keys,
+		next:     make([]time.Time, len(keys)),
+		interval: time.Minute / time.Duration(perMinute),
+	}
+}
+
+// wait blocks until a request can be made and returns the key to make it with.
+// The key available the soonest is used.
+func (l *keyLimiter) wait(ctx context.Context) (string, error) {
+	l.mu.Lock()
+	best := 0
+	for i := range l.next {
+		if l.next[i].Before(l.next[best]) {
+			best = i
+		}
+	}
+	now := time.Now()
+	at := l.next[best]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[best] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-time.After(time.Until(at)):
+		return l.keys[best], nil
+	}
+}
+
+// penalize delays the next request made with key by d, used when VirusTotal
+// reports the key's quota as exceeded.
+func (l *keyLimiter) penalize(key string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, k := range l.keys {
+		if k == key {
+			if at := time.Now().Add(d); at.After(l.next[i]) {
+				l.next[i] = at
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package virustotal
+
+type apiResponse struct {
+	Data []urlObject `json:"data"`
+	Meta struct {
+		Cursor string `json:"cursor"`
+	} `json:"meta"`
+	Error *apiError `json:"error"`
+}
+
+type urlObject struct {
+	ID         string `json:"id"`
+	Attributes struct {
+		URL                  string `json:"url"`
+		LastAnalysisDate     int64  `json:"last_analysis_date"`
+		LastHTTPResponseCode int    `json:"last_http_response_code"`
+		ContentType          string `json:"last_http_response_content_type"`
+	} `json:"attributes"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
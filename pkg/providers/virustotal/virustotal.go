@@ -0,0 +1,154 @@
+package virustotal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/lc/gau/v2/pkg/httpclient"
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	Name = "virustotal"
+
+	// DefaultBaseURL is the location of the VirusTotal API
+	DefaultBaseURL = "https://www.virustotal.com/"
+
+	// pageSize is the largest number of urls VirusTotal returns per page
+	pageSize = 40
+
+	// quotaBackoff is how long a key is left alone after exceeding its quota
+	quotaBackoff = time.Minute
+)
+
+var _ providers.Provider = (*Client)(nil)
+
+type Client struct {
+	config *providers.Config
+
+	baseURL string
+	limiter *keyLimiter
+}
+
+// New returns a VirusTotal Client. Requests are spread across the configured
+// API keys, each limited to its per-minute quota.
+func New(c *providers.Config) *Client {
+	return &Client{
+		config:  c,
+		baseURL: providers.BaseURL(c.VirusTotal.Host, DefaultBaseURL),
+		limiter: newKeyLimiter(c.VirusTotal.APIKeys, c.VirusTotal.RateLimit),
+	}
+}
+
+func (c *Client) Name() string {
+	return Name
+}
+
+// Fetch fetches the urls VirusTotal knows for a domain, following the cursor
+// pagination, and sends them to a channel.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	if len(c.limiter.keys) == 0 {
+		return errors.New("an api key is required, set apikeys in [virustotal]")
+	}
+
+	var cursor string
+	for page := uint(0); ; page++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			logrus.WithFields(logrus.Fields{"provider": Name, "page": page}).Infof("fetching %s", domain)
+			result, err := c.request(ctx, c.formatURL(domain, cursor))
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return nil
+				}
+				return fmt.Errorf("failed to fetch virustotal(%d): %s", page, err)
+			}
+
+			for _, obj := range result.Data {
+				if obj.Attributes.URL == "" || !providers.InScope(obj.Attributes.URL, domain, c.config.Subdomains(ctx)) {
+					continue
+				}
+				results <- toResult(obj)
+			}
+
+			if result.Meta.Cursor == "" || len(result.Data) == 0 {
+				return nil
+			}
+			cursor = result.Meta.Cursor
+		}
+	}
+}
+
+// toResult maps a VirusTotal url object, keeping its last analysis date and response code
+func toResult(obj urlObject) providers.Result {
+	r := providers.Result{
+		URL:      obj.Attributes.URL,
+		Source:   Name,
+		MimeType: obj.Attributes.ContentType,
+		Meta:     make(map[string]string),
+	}
+	if obj.Attributes.LastAnalysisDate != 0 {
+		r.Timestamp = time.Unix(obj.Attributes.LastAnalysisDate, 0).UTC().Format(time.RFC3339)
+		r.Meta["last_analysis_date"] = r.Timestamp
+	}
+	if obj.Attributes.LastHTTPResponseCode != 0 {
+		r.StatusCode = strconv.Itoa(obj.Attributes.LastHTTPResponseCode)
+		r.Meta["http_code"] = r.StatusCode
+	}
+	return r
+}
+
+// request fetches apiURL with the next available key. Failed requests, and requests
+// rejected because a key's quota was exceeded, are retried up to MaxRetries times.
+// Every attempt waits for the limiter, so retries count against the key quotas too.
+func (c *Client) request(ctx context.Context, apiURL string) (apiResponse, error) {
+	for attempt := uint(0); ; attempt++ {
+		var result apiResponse
+
+		key, err := c.limiter.wait(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		// retries are made here rather than by httpclient, which would skip the limiter
+		resp, err := httpclient.Do(c.config.Client, apiURL, 0, c.config.Timeout, httpclient.Header{Key: "x-apikey", Value: key})
+		if errors.Is(err, httpclient.ErrRateLimited) {
+			if attempt >= c.config.MaxRetries {
+				return result, fmt.Errorf("quota exceeded after %d attempts", attempt+1)
+			}
+			logrus.WithField("provider", Name).Infof("quota exceeded, retrying in %s", quotaBackoff)
+			c.limiter.penalize(key, quotaBackoff)
+			continue
+		}
+		if resp != nil && len(resp.Body) > 0 {
+			// errors are described in the body, decode it either way
+			if derr := jsoniter.Unmarshal(resp.Body, &result); derr != nil && err == nil {
+				return result, fmt.Errorf("failed to decode virustotal result: %s", derr)
+			}
+		}
+		if result.Error != nil {
+			return result, fmt.Errorf("%s: %s", result.Error.Code, result.Error.Message)
+		}
+		if err != nil && !errors.Is(err, httpclient.ErrBadRequest) && attempt < c.config.MaxRetries {
+			logrus.WithField("provider", Name).Infof("request failed, retrying: %v", err)
+			continue
+		}
+		return result, err
+	}
+}
+
+func (c *Client) formatURL(domain, cursor string) string {
+	apiURL := fmt.Sprintf("%sapi/v3/domains/%s/urls?limit=%d", c.baseURL, domain, pageSize)
+	if cursor != "" {
+		apiURL += "&cursor=" + url.QueryEscape(cursor)
+	}
+	return apiURL
+}
@@ -0,0 +1,182 @@
+package virustotal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/valyala/fasthttp"
+)
+
+// perMinute is the quota used in tests, spacing the requests of a key by 100ms
+const perMinute = 600
+
+// server records the time of every request and answers with the responses in order,
+// repeating the last one
+type server struct {
+	mu        sync.Mutex
+	requests  []time.Time
+	keys      []string
+	responses []response
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	n := len(s.requests)
+	s.requests = append(s.requests, time.Now())
+	s.keys = append(s.keys, r.Header.Get("x-apikey"))
+	s.mu.Unlock()
+
+	resp := s.responses[len(s.responses)-1]
+	if n < len(s.responses) {
+		resp = s.responses[n]
+	}
+	w.WriteHeader(resp.status)
+	w.Write([]byte(resp.body))
+}
+
+func newTestClient(t *testing.T, s *server, keys []string, maxRetries uint) *Client {
+	t.Helper()
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return New(&providers.Config{
+		Timeout:    5,
+		MaxRetries: maxRetries,
+		Client:     &fasthttp.Client{},
+		VirusTotal: providers.VirusTotal{Host: srv.URL, APIKeys: keys, RateLimit: perMinute},
+	})
+}
+
+func TestRequestRetriesWaitForLimiter(t *testing.T) {
+	page := `{"data": [{"attributes": {"url": "https://example.com/a"}}]}`
+	tests := []struct {
+		name       string
+		responses  []response
+		maxRetries uint
+		requests   int
+		wantErr    bool
+	}{
+		{
+			name:       "server errors",
+			responses:  []response{{500, ""}, {502, ""}, {500, ""}, {200, page}},
+			maxRetries: 5,
+			requests:   4,
+		},
+		{
+			name:       "out of retries",
+			responses:  []response{{500, ""}},
+			maxRetries: 2,
+			requests:   3,
+			wantErr:    true,
+		},
+		{
+			name:       "api error",
+			responses:  []response{{404, `{"error": {"code": "NotFoundError", "message": "Domain not found"}}`}},
+			maxRetries: 5,
+			requests:   1,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{responses: tt.responses}
+			c := newTestClient(t, s, []string{"key"}, tt.maxRetries)
+
+			_, err := c.request(context.Background(), c.formatURL("example.com", ""))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("request() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(s.requests) != tt.requests {
+				t.Fatalf("request() made %d requests, want %d", len(s.requests), tt.requests)
+			}
+			interval := time.Minute / perMinute
+			for i := 1; i < len(s.requests); i++ {
+				// allow for timer jitter
+				if gap := s.requests[i].Sub(s.requests[i-1]); gap < interval-10*time.Millisecond {
+					t.Errorf("request %d made %s after the previous one, want at least %s", i, gap, interval)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyLimiter(t *testing.T) {
+	l := newKeyLimiter([]string{"a", "b"}, perMinute)
+	interval := time.Minute / perMinute
+
+	start := time.Now()
+	var keys []string
+	for i := 0; i < 4; i++ {
+		key, err := l.wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	if want := []string{"a", "b", "a", "b"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("wait() keys = %v, want %v", keys, want)
+	}
+	if elapsed := time.Since(start); elapsed < interval-10*time.Millisecond {
+		t.Errorf("four requests over two keys took %s, want at least %s", elapsed, interval)
+	}
+
+	// a penalized key is skipped while the other one is available
+	l.penalize("a", time.Hour)
+	for i := 0; i < 2; i++ {
+		if key, _ := l.wait(context.Background()); key != "b" {
+			t.Errorf("wait() = %s after penalizing a, want b", key)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.penalize("b", time.Hour)
+	if _, err := l.wait(ctx); err == nil {
+		t.Error("wait() returned no error once canceled")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	s := &server{responses: []response{
+		{200, `{"data": [
+			{"attributes": {"url": "https://example.com/a", "last_analysis_date": 1577836800, "last_http_response_code": 200}},
+			{"attributes": {"url": "https://notexample.com/"}}
+		], "meta": {"cursor": "next"}}`},
+		{200, `{"data": [{"attributes": {"url": "https://www.example.com/b"}}]}`},
+	}}
+	c := newTestClient(t, s, []string{"a", "b"}, 0)
+
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+
+	var got []providers.Result
+	for r := range results {
+		got = append(got, r)
+	}
+	want := []providers.Result{{
+		URL:        "https://example.com/a",
+		Source:     Name,
+		Timestamp:  "2020-01-01T00:00:00Z",
+		StatusCode: "200",
+		Meta:       map[string]string{"last_analysis_date": "2020-01-01T00:00:00Z", "http_code": "200"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(s.keys, []string{"a", "b"}) {
+		t.Errorf("Fetch() used keys %v, want a then b", s.keys)
+	}
+}
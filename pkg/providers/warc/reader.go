@@ -0,0 +1,108 @@
+package warc
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// record holds the parts of a WARC record gau uses
+type record struct {
+	Type       string
+	TargetURI  string
+	Date       string
+	StatusCode string
+	MimeType   string
+}
+
+// reader reads the records of a plain or gzip compressed WARC stream
+type reader struct {
+	r *bufio.Reader
+}
+
+// newReader returns a reader for r, decompressing it if it starts with a gzip header
+func newReader(r io.Reader) (*reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(gz)
+	}
+	return &reader{r: br}, nil
+}
+
+// next returns the next record, or io.EOF once the stream is exhausted
+func (rd *reader) next() (record, error) {
+	var rec record
+
+	// skip the blank lines separating records
+	for {
+		line, err := rd.r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && strings.TrimSpace(line) == "" {
+				return rec, io.EOF
+			}
+			return rec, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "WARC/") {
+			return rec, fmt.Errorf("invalid record version %q", line)
+		}
+		break
+	}
+
+	header, err := textproto.NewReader(rd.r).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return rec, err
+	}
+	rec.Type = header.Get("WARC-Type")
+	rec.TargetURI = strings.Trim(header.Get("WARC-Target-URI"), "<>")
+	rec.Date = header.Get("WARC-Date")
+
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return rec, fmt.Errorf("invalid content length: %v", err)
+	}
+
+	block := io.LimitReader(rd.r, length)
+	if rec.Type == "response" && strings.HasPrefix(header.Get("Content-Type"), "application/http") {
+		rec.StatusCode, rec.MimeType = parseHTTPResponse(block)
+	}
+	// discard whatever is left of the block
+	if _, err = io.Copy(io.Discard, block); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+// parseHTTPResponse reads the status code and content type of an HTTP response block.
+// The rest of the block is left for the caller to discard.
+func parseHTTPResponse(r io.Reader) (string, string) {
+	br := bufio.NewReader(r)
+	status, err := br.ReadString('\n')
+	if err != nil {
+		return "", ""
+	}
+	fields := strings.Fields(status)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return "", ""
+	}
+
+	header, _ := textproto.NewReader(br).ReadMIMEHeader()
+	mime := header.Get("Content-Type")
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = mime[:i]
+	}
+	return fields[1], strings.ToLower(strings.TrimSpace(mime))
+}
@@ -0,0 +1,87 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// warcRecord formats a WARC record with the given headers and block
+func warcRecord(headers, block string) string {
+	return fmt.Sprintf("WARC/1.0\r\n%sContent-Length: %d\r\n\r\n%s\r\n\r\n", headers, len(block), block)
+}
+
+func TestReader(t *testing.T) {
+	response := "HTTP/1.1 404 Not Found\r\nContent-Type: Text/HTML; charset=utf-8\r\n\r\n<html>not found</html>"
+	stream := warcRecord("WARC-Type: warcinfo\r\n", "software: test\r\n") +
+		warcRecord("WARC-Type: response\r\nWARC-Target-URI: <https://example.com/a>\r\nWARC-Date: 2020-01-01T00:00:00Z\r\nContent-Type: application/http; msgtype=response\r\n", response) +
+		warcRecord("WARC-Type: response\r\nWARC-Target-URI: https://example.com/b\r\nContent-Type: application/http; msgtype=response\r\n", "garbage\r\n") +
+		warcRecord("WARC-Type: request\r\nWARC-Target-URI: https://example.com/c\r\n", "GET /c HTTP/1.1\r\n\r\n")
+
+	want := []record{
+		{Type: "warcinfo"},
+		{Type: "response", TargetURI: "https://example.com/a", Date: "2020-01-01T00:00:00Z", StatusCode: "404", MimeType: "text/html"},
+		{Type: "response", TargetURI: "https://example.com/b"},
+		{Type: "request", TargetURI: "https://example.com/c"},
+	}
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(stream))
+	w.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "plain", data: []byte(stream)},
+		{name: "gzip", data: gz.Bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rd, err := newReader(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("newReader() error = %v", err)
+			}
+			var got []record
+			for {
+				rec, err := rd.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("next() error = %v", err)
+				}
+				got = append(got, rec)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("records = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+	}{
+		{name: "invalid version", stream: "HTTP/1.1 200 OK\r\n\r\n"},
+		{name: "invalid length", stream: "WARC/1.0\r\nWARC-Type: response\r\nContent-Length: x\r\n\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rd, err := newReader(strings.NewReader(tt.stream))
+			if err != nil {
+				t.Fatalf("newReader() error = %v", err)
+			}
+			if _, err = rd.next(); err == nil || err == io.EOF {
+				t.Errorf("next() error = %v, want a parse error", err)
+			}
+		})
+	}
+}
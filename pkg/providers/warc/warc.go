@@ -0,0 +1,229 @@
+package warc
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/sirupsen/logrus"
+)
+
+const Name = "warc"
+
+var _ providers.Provider = (*Client)(nil)
+
+// Client reads the records of local WARC and WACZ files
+type Client struct {
+	filters providers.Filters
+	config  *providers.Config
+
+	paths []string
+}
+
+func New(c *providers.Config, filters providers.Filters) *Client {
+	return &Client{
+		filters: filters,
+		config:  c,
+		paths:   c.WARC.Paths,
+	}
+}
+
+func (c *Client) Name() string {
+	return Name
+}
+
+// Fetch reads every configured file and sends the target URIs of the response and
+// request records for a domain to a channel. Each url is sent once, request records
+// only for urls without a response.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("no warc or wacz files found, set paths in [warc]")
+	}
+
+	s := &state{
+		seen:     make(map[string]struct{}),
+		requests: make(map[string]providers.Result),
+	}
+	var errs []error
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return nil
+		}
+		logrus.WithFields(logrus.Fields{"provider": Name, "file": file}).Infof("fetching %s", domain)
+
+		var err error
+		if strings.EqualFold(filepath.Ext(file), ".wacz") {
+			err = c.readWACZ(ctx, file, domain, s, results)
+		} else {
+			err = c.readFile(ctx, file, domain, s, results)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", file, err))
+		}
+	}
+
+	urls := make([]string, 0, len(s.requests))
+	for u := range s.requests {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	for _, u := range urls {
+		results <- s.requests[u]
+	}
+	return errors.Join(errs...)
+}
+
+// state tracks the urls sent for a domain, and the requests still waiting for a response
+type state struct {
+	seen     map[string]struct{}
+	requests map[string]providers.Result
+}
+
+// files expands the configured paths. Directories are searched for WARC and WACZ files.
+func (c *Client) files() ([]string, error) {
+	var files []string
+	for _, pattern := range c.paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %v", pattern, err)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				files = append(files, match)
+				continue
+			}
+			err = filepath.WalkDir(match, func(p string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() && isArchive(p) {
+					files = append(files, p)
+				}
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isArchive reports whether name looks like a WARC or WACZ file
+func isArchive(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".warc") || strings.HasSuffix(name, ".warc.gz") || strings.HasSuffix(name, ".wacz")
+}
+
+func (c *Client) readFile(ctx context.Context, file, domain string, s *state, results chan providers.Result) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.read(ctx, f, file, domain, s, results)
+}
+
+// readWACZ reads the WARC files stored under archive/ in a WACZ package
+func (c *Client) readWACZ(ctx context.Context, file, domain string, s *state, results chan providers.Result) error {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, entry := range zr.File {
+		if path.Dir(entry.Name) != "archive" || !isArchive(entry.Name) {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = c.read(ctx, rc, file+"#"+entry.Name, domain, s, results)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read sends the in-scope records of a WARC stream
+func (c *Client) read(ctx context.Context, r io.Reader, name, domain string, s *state, results chan providers.Result) error {
+	rd, err := newReader(r)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		rec, err := rd.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if rec.Type != "response" && rec.Type != "request" {
+			continue
+		}
+		if !providers.InScope(rec.TargetURI, domain, c.config.Subdomains(ctx)) {
+			continue
+		}
+
+		result := providers.Result{
+			URL:        rec.TargetURI,
+			Source:     Name,
+			Timestamp:  formatDate(rec.Date),
+			StatusCode: rec.StatusCode,
+			MimeType:   rec.MimeType,
+			Meta: map[string]string{
+				"file":   name,
+				"record": rec.Type,
+			},
+		}
+		if !c.filters.Match(result) {
+			continue
+		}
+		if _, ok := s.seen[result.URL]; ok {
+			continue
+		}
+		if rec.Type == "request" {
+			if _, ok := s.requests[result.URL]; !ok {
+				s.requests[result.URL] = result
+			}
+			continue
+		}
+		s.seen[result.URL] = struct{}{}
+		delete(s.requests, result.URL)
+		results <- result
+	}
+}
+
+// formatDate normalizes WARC-Date values, which may carry fractional seconds, to RFC3339
+func formatDate(date string) string {
+	t, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		return date
+	}
+	return t.UTC().Format(time.RFC3339)
+}
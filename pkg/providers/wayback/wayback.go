@@ -1,9 +1,15 @@
 package wayback
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/lc/gau/v2/pkg/httpclient"
@@ -13,74 +19,392 @@ import (
 
 const (
 	Name = "wayback"
+
+	// DefaultBaseURL is the location of the Wayback Machine
+	DefaultBaseURL = "https://web.archive.org/"
+
+	// DefaultCollapse is the collapse strategy used when none is configured
+	DefaultCollapse = "urlkey"
+
+	// PaginationResumeKey walks the results using the CDX resumption key
+	PaginationResumeKey = "resumekey"
+	// PaginationPage walks the results using numbered pages, which can be fetched in parallel
+	PaginationPage = "page"
+	// PaginationNone fetches all results in a single request
+	PaginationNone = "none"
+
+	// DefaultConcurrency is the number of pages fetched at once when none is configured
+	DefaultConcurrency = 4
+
+	// number of rows requested per resumption key page
+	pageLimit = 10000
+	// number of index blocks requested per numbered page
+	pageSize = 100
 )
 
+// fieldOrder lists the result fields requested from a CDX server
+var fieldOrder = []string{"timestamp", "url", "statuscode", "mimetype", "digest", "length"}
+
+// DefaultFields maps the result fields to the field names used by the Wayback Machine
+var DefaultFields = map[string]string{
+	"timestamp":  "timestamp",
+	"url":        "original",
+	"statuscode": "statuscode",
+	"mimetype":   "mimetype",
+	"digest":     "digest",
+	"length":     "length",
+}
+
 // verify interface compliance
-var _ providers.Provider = (*Client)(nil)
+var _ providers.Paginator = (*Client)(nil)
 
-// Client is the structure that holds the WaybackFilters and the Client's configuration
+// Client is the structure that holds the WaybackFilters and the Client's configuration.
+// It queries any CDX server, the Wayback Machine being the default.
 type Client struct {
 	filters providers.Filters
 	config  *providers.Config
+
+	name        string
+	endpoint    string
+	headers     []httpclient.Header
+	fields      map[string]string
+	collapse    string
+	pagination  string
+	concurrency uint
 }
 
 func New(config *providers.Config, filters providers.Filters) *Client {
-	return &Client{filters, config}
+	return NewCDX(Name, providers.CDX{
+		URL:         providers.BaseURL(config.Wayback.Host, DefaultBaseURL) + "cdx/search/cdx",
+		Collapse:    config.Wayback.Collapse,
+		Pagination:  config.Wayback.Pagination,
+		Concurrency: config.Wayback.Concurrency,
+	}, config, filters)
+}
+
+// NewCDX returns a Client for the CDX server described by cdx, reporting its results as name.
+func NewCDX(name string, cdx providers.CDX, config *providers.Config, filters providers.Filters) *Client {
+	c := &Client{
+		filters:     filters,
+		config:      config,
+		name:        name,
+		endpoint:    cdx.URL,
+		fields:      make(map[string]string, len(DefaultFields)),
+		collapse:    cdx.Collapse,
+		pagination:  cdx.Pagination,
+		concurrency: cdx.Concurrency,
+	}
+
+	keys := make([]string, 0, len(cdx.Headers))
+	for key := range cdx.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		c.headers = append(c.headers, httpclient.Header{Key: key, Value: cdx.Headers[key]})
+	}
+
+	for field, name := range DefaultFields {
+		c.fields[field] = name
+		if mapped, ok := cdx.Fields[field]; ok && mapped != "" {
+			c.fields[field] = mapped
+		}
+	}
+
+	if c.collapse == "" {
+		c.collapse = DefaultCollapse
+	}
+	if c.pagination == "" {
+		c.pagination = PaginationResumeKey
+	}
+	if c.concurrency == 0 {
+		c.concurrency = DefaultConcurrency
+	}
+	return c
 }
 
 func (c *Client) Name() string {
-	return Name
+	return c.name
 }
 
-// waybackResult holds the response from the wayback API
-type waybackResult [][]string
+// cdxPage holds the records of a CDX response mapped to their field names
+type cdxPage struct {
+	records   []map[string]string
+	resumeKey string
+}
 
 // Fetch fetches all urls for a given domain and sends them to a channel.
-// It returns an error should one occur.
-func (c *Client) Fetch(ctx context.Context, domain string, results chan string) error {
+// By default pages are walked using the CDX resumption key, so the results are
+// complete even when filters are applied, falling back to a single request for servers
+// that return no resumption key. It returns an error should one occur.
+func (c *Client) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	switch c.pagination {
+	case PaginationPage:
+		return c.fetchPages(ctx, domain, results)
+	case PaginationNone:
+		return c.fetchAll(ctx, domain, results)
+	}
+
+	var resumeKey string
 	for page := uint(0); ; page++ {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
-			logrus.WithFields(logrus.Fields{"provider": Name, "page": page}).Infof("fetching %s", domain)
-			apiURL := c.formatURL(domain, page)
-			// make HTTP request
-			resp, err := httpclient.MakeRequest(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout)
+			logrus.WithFields(logrus.Fields{"provider": c.name, "page": page}).Infof("fetching %s", domain)
+			apiURL := c.formatURL(ctx, domain) + "&showResumeKey=true&limit=" + strconv.Itoa(pageLimit)
+			if resumeKey != "" {
+				apiURL += "&resumeKey=" + url.QueryEscape(resumeKey)
+			}
+			result, err := c.request(apiURL, page)
 			if err != nil {
 				if errors.Is(err, httpclient.ErrBadRequest) {
 					return nil
 				}
-				return fmt.Errorf("failed to fetch wayback results page %d: %s", page, err)
+				return err
 			}
-			var result waybackResult
-			if err = jsoniter.Unmarshal(resp, &result); err != nil {
-				return fmt.Errorf("failed to decode wayback results for page %d: %s", page, err)
+
+			if result.resumeKey == "" {
+				// servers without resumption keys, like pywb, silently cut the results
+				// at the limit, so a full first page is fetched again without a limit
+				if page == 0 && len(result.records) >= pageLimit {
+					logrus.WithFields(logrus.Fields{"provider": c.name}).Warnf("no resume key returned for %s, fetching all results at once; set pagination to page or none for this server", domain)
+					return c.fetchAll(ctx, domain, results)
+				}
+				c.sendResults(result, results)
+				return nil
 			}
+			c.sendResults(result, results)
+			resumeKey = result.resumeKey
+		}
+	}
+}
 
+// fetchAll fetches all results for domain in a single request.
+func (c *Client) fetchAll(ctx context.Context, domain string, results chan providers.Result) error {
+	logrus.WithFields(logrus.Fields{"provider": c.name}).Infof("fetching %s", domain)
+	result, err := c.request(c.formatURL(ctx, domain), 0)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrBadRequest) {
+			return nil
+		}
+		return err
+	}
+	c.sendResults(result, results)
+	return nil
+}
+
+// fetchPages walks the numbered pages of the CDX API until an empty page is returned.
+func (c *Client) fetchPages(ctx context.Context, domain string, results chan providers.Result) error {
+	for page := uint(0); ; page++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			result, err := c.fetchPage(ctx, domain, page)
+			if err != nil {
+				if errors.Is(err, httpclient.ErrBadRequest) {
+					return nil
+				}
+				return err
+			}
 			// check if there's results, wayback's pagination response
 			// is not always correct when using a filter
-			if len(result) == 0 {
-				break
+			if len(result.records) == 0 {
+				return nil
 			}
+			c.sendResults(result, results)
+		}
+	}
+}
+
+// Pages returns the number of pages reported by the CDX API for domain.
+// It returns providers.ErrNotPaginated unless page based pagination is configured.
+func (c *Client) Pages(ctx context.Context, domain string) (uint, error) {
+	if c.pagination != PaginationPage {
+		return 0, providers.ErrNotPaginated
+	}
+
+	resp, err := httpclient.MakeRequest(c.config.Client, c.pagedURL(ctx, domain)+"&showNumPages=true", c.config.MaxRetries, c.config.Timeout, c.headers...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch %s page count: %s", c.name, err)
+	}
 
-			// output results
-			// Slicing as [1:] to skip first result by default
-			for _, entry := range result[1:] {
-				results <- entry[0]
+	// the Wayback Machine returns a bare number, pywb returns a json object
+	resp = bytes.TrimSpace(resp)
+	if pages, err := strconv.ParseUint(string(resp), 10, 64); err == nil {
+		return uint(pages), nil
+	}
+	var p struct {
+		Pages uint `json:"pages"`
+	}
+	if err = jsoniter.Unmarshal(resp, &p); err != nil {
+		return 0, fmt.Errorf("failed to decode %s page count: %s", c.name, err)
+	}
+	return p.Pages, nil
+}
+
+// FetchPage fetches a single numbered page of results for domain.
+func (c *Client) FetchPage(ctx context.Context, domain string, page uint, results chan providers.Result) error {
+	result, err := c.fetchPage(ctx, domain, page)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrBadRequest) {
+			return nil
+		}
+		return err
+	}
+	c.sendResults(result, results)
+	return nil
+}
+
+// MaxConcurrency returns the maximum number of pages fetched at once.
+func (c *Client) MaxConcurrency() uint {
+	return c.concurrency
+}
+
+func (c *Client) fetchPage(ctx context.Context, domain string, page uint) (cdxPage, error) {
+	logrus.WithFields(logrus.Fields{"provider": c.name, "page": page}).Infof("fetching %s", domain)
+	return c.request(fmt.Sprintf("%s&page=%d", c.pagedURL(ctx, domain), page), page)
+}
+
+// pagedURL returns the query of the numbered pages of domain. The page count is requested
+// with the same page size as the pages, or pages would be skipped or fetched twice.
+func (c *Client) pagedURL(ctx context.Context, domain string) string {
+	return fmt.Sprintf("%s&pageSize=%d", c.formatURL(ctx, domain), pageSize)
+}
+
+func (c *Client) request(apiURL string, page uint) (cdxPage, error) {
+	// make HTTP request
+	resp, err := httpclient.MakeRequest(c.config.Client, apiURL, c.config.MaxRetries, c.config.Timeout, c.headers...)
+	if err != nil {
+		if errors.Is(err, httpclient.ErrBadRequest) {
+			return cdxPage{}, err
+		}
+		return cdxPage{}, fmt.Errorf("failed to fetch %s results page %d: %s", c.name, page, err)
+	}
+
+	result, err := decodePage(resp)
+	if err != nil {
+		return cdxPage{}, fmt.Errorf("failed to decode %s results for page %d: %s", c.name, page, err)
+	}
+	return result, nil
+}
+
+// decodePage decodes a CDX response. The Wayback Machine and OpenWayback return an
+// array of rows whose first row holds the field names, pywb returns a json object per line.
+func decodePage(resp []byte) (cdxPage, error) {
+	var page cdxPage
+
+	resp = bytes.TrimSpace(resp)
+	if len(resp) == 0 {
+		return page, nil
+	}
+
+	if resp[0] != '[' {
+		sc := bufio.NewScanner(bytes.NewReader(resp))
+		sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for sc.Scan() {
+			if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+				continue
+			}
+			var object map[string]interface{}
+			if err := jsoniter.Unmarshal(sc.Bytes(), &object); err != nil {
+				return page, err
 			}
+			record := make(map[string]string, len(object))
+			for field, value := range object {
+				if s, ok := value.(string); ok {
+					record[field] = s
+				} else {
+					record[field] = fmt.Sprint(value)
+				}
+			}
+			page.records = append(page.records, record)
+		}
+		return page, sc.Err()
+	}
+
+	var rows [][]string
+	if err := jsoniter.Unmarshal(resp, &rows); err != nil {
+		return page, err
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	// the first row holds the field names
+	header := rows[0]
+	for i, row := range rows[1:] {
+		// an empty row separates the results from the resumption key
+		if len(row) == 0 {
+			rest := rows[i+2:]
+			if len(rest) > 0 && len(rest[0]) > 0 {
+				page.resumeKey = rest[0][0]
+			}
+			break
+		}
+		record := make(map[string]string, len(header))
+		for j, field := range header {
+			if j < len(row) {
+				record[field] = row[j]
+			}
+		}
+		page.records = append(page.records, record)
+	}
+	return page, nil
+}
+
+// sendResults outputs the records of a CDX response.
+func (c *Client) sendResults(page cdxPage, results chan providers.Result) {
+	get := func(record map[string]string, field string) string {
+		value := record[c.fields[field]]
+		if value == "-" {
+			return ""
+		}
+		return value
+	}
+
+	for _, record := range page.records {
+		u := get(record, "url")
+		if u == "" {
+			continue
+		}
+		results <- providers.Result{
+			URL:        u,
+			Source:     c.name,
+			Timestamp:  providers.CDXTime(get(record, "timestamp")),
+			StatusCode: get(record, "statuscode"),
+			MimeType:   get(record, "mimetype"),
+			Digest:     get(record, "digest"),
+			Length:     get(record, "length"),
 		}
 	}
 }
 
-// formatUrl returns a formatted URL for the Wayback API
-func (c *Client) formatURL(domain string, page uint) string {
-	if c.config.IncludeSubdomains {
+// formatUrl returns a formatted URL for the CDX API
+func (c *Client) formatURL(ctx context.Context, domain string) string {
+	if c.config.Subdomains(ctx) {
 		domain = "*." + domain
 	}
+
+	var collapse string
+	if c.collapse != "none" {
+		collapse = "&collapse=" + url.QueryEscape(c.collapse)
+	}
+
+	fl := make([]string, len(fieldOrder))
+	for i, field := range fieldOrder {
+		fl[i] = c.fields[field]
+	}
+
+	separator := "?"
+	if strings.Contains(c.endpoint, "?") {
+		separator = "&"
+	}
+
 	filterParams := c.filters.GetParameters(true)
 	return fmt.Sprintf(
-		"https://web.archive.org/cdx/search/cdx?url=%s/*&output=json&collapse=urlkey&fl=original&pageSize=100&page=%d",
-		domain, page,
-	) + filterParams
+		"%s%surl=%s/*&output=json&fl=%s",
+		c.endpoint, separator, domain, strings.Join(fl, ","),
+	) + collapse + filterParams
 }
@@ -0,0 +1,190 @@
+package wayback
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/valyala/fasthttp"
+)
+
+func newTestClient(t *testing.T, cdx providers.CDX, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cdx.URL = srv.URL + "/cdx/search/cdx"
+	return NewCDX("test", cdx, &providers.Config{Timeout: 5, Client: &fasthttp.Client{}}, providers.Filters{})
+}
+
+func TestPagesMatchPageSize(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []url.Values
+	)
+	c := newTestClient(t, providers.CDX{Pagination: PaginationPage}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		mu.Unlock()
+		if r.URL.Query().Get("showNumPages") == "true" {
+			w.Write([]byte("3\n"))
+			return
+		}
+		w.Write([]byte(`[["original"],["https://example.com/"]]`))
+	})
+
+	pages, err := c.Pages(context.Background(), "example.com")
+	if err != nil || pages != 3 {
+		t.Fatalf("Pages() = %d, %v, want 3", pages, err)
+	}
+	results := make(chan providers.Result, 10)
+	if err := c.FetchPage(context.Background(), "example.com", 2, results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Errorf("FetchPage() sent %d results, want 1", len(results))
+	}
+
+	count, page := queries[0], queries[1]
+	if count.Get("pageSize") == "" || count.Get("pageSize") != page.Get("pageSize") {
+		t.Errorf("page count requested with pageSize %q, pages fetched with %q", count.Get("pageSize"), page.Get("pageSize"))
+	}
+	if page.Get("page") != "2" {
+		t.Errorf("FetchPage() requested page %q, want 2", page.Get("page"))
+	}
+	// apart from the page parameters, both requests are the same query
+	count.Del("showNumPages")
+	page.Del("page")
+	if count.Encode() != page.Encode() {
+		t.Errorf("page count query %q differs from page query %q", count.Encode(), page.Encode())
+	}
+}
+
+func TestFetchWithoutResumeKey(t *testing.T) {
+	// pywb style server: json lines, no resumption key, results cut at the limit
+	var requests []url.Values
+	c := newTestClient(t, providers.CDX{}, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query())
+		n := pageLimit + 5
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			n, _ = strconv.Atoi(limit)
+		}
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(w, "{\"original\": \"https://example.com/%d\"}\n", i)
+		}
+	})
+
+	results := make(chan providers.Result, pageLimit+10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != pageLimit+5 {
+		t.Errorf("Fetch() sent %d results, want %d", len(results), pageLimit+5)
+	}
+	if len(requests) != 2 || requests[1].Get("limit") != "" {
+		t.Errorf("Fetch() made requests %v, want a limited one then an unlimited one", requests)
+	}
+}
+
+func TestFetchResumeKey(t *testing.T) {
+	c := newTestClient(t, providers.CDX{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("resumeKey") == "" {
+			w.Write([]byte(`[["original"],["https://example.com/a"],[],["next"]]`))
+			return
+		}
+		w.Write([]byte(`[["original"],["https://example.com/b"]]`))
+	})
+
+	results := make(chan providers.Result, 10)
+	if err := c.Fetch(context.Background(), "example.com", results); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+	var got []string
+	for result := range results {
+		got = append(got, result.URL)
+	}
+	if want := []string{"https://example.com/a", "https://example.com/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fetch() = %v, want %v", got, want)
+	}
+}
+
+func TestDecodePage(t *testing.T) {
+	tests := []struct {
+		name      string
+		resp      string
+		records   []map[string]string
+		resumeKey string
+		wantErr   bool
+	}{
+		{
+			name: "empty",
+			resp: " \n",
+		},
+		{
+			name: "rows",
+			resp: `[["original","timestamp"],["https://example.com/","20200101000000"],["https://example.com/a","20210101000000"]]`,
+			records: []map[string]string{
+				{"original": "https://example.com/", "timestamp": "20200101000000"},
+				{"original": "https://example.com/a", "timestamp": "20210101000000"},
+			},
+		},
+		{
+			name: "short row",
+			resp: `[["original","statuscode"],["https://example.com/"]]`,
+			records: []map[string]string{
+				{"original": "https://example.com/"},
+			},
+		},
+		{
+			name: "resumption key",
+			resp: `[["original"],["https://example.com/"],[],["com,example)/+20200101000000"]]`,
+			records: []map[string]string{
+				{"original": "https://example.com/"},
+			},
+			resumeKey: "com,example)/+20200101000000",
+		},
+		{
+			name: "pywb lines",
+			resp: "{\"url\": \"https://example.com/\", \"status\": \"200\"}\n\n{\"url\": \"https://example.com/a\", \"length\": 42}\n",
+			records: []map[string]string{
+				{"url": "https://example.com/", "status": "200"},
+				{"url": "https://example.com/a", "length": "42"},
+			},
+		},
+		{
+			name:    "invalid line",
+			resp:    "{\"url\": \"https://example.com/\"}\nnot json\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid rows",
+			resp:    `[["original"],`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := decodePage([]byte(tt.resp))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(page.records, tt.records) {
+				t.Errorf("decodePage() records = %v, want %v", page.records, tt.records)
+			}
+			if page.resumeKey != tt.resumeKey {
+				t.Errorf("decodePage() resumeKey = %q, want %q", page.resumeKey, tt.resumeKey)
+			}
+		})
+	}
+}
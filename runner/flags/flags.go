@@ -8,43 +8,261 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/lc/gau/v2/pkg/output"
 	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/lc/gau/v2/pkg/providers/commoncrawl"
+	"github.com/lc/gau/v2/pkg/providers/wayback"
 	"github.com/lynxsecurity/pflag"
 	"github.com/lynxsecurity/viper"
+	"github.com/mitchellh/mapstructure"
 	log "github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpproxy"
 )
 
+// collapseRegex matches CDX collapse strategies such as urlkey, digest or timestamp:8
+var collapseRegex = regexp.MustCompile(`^[a-z]+(:[0-9]+)?$`)
+
 type URLScanConfig struct {
+	Host     string `mapstructure:"host"`
+	APIKey   string `mapstructure:"apikey"`
+	MaxWait  uint   `mapstructure:"maxwait"`
+	Query    string `mapstructure:"query"`
+	PageSize uint   `mapstructure:"pagesize"`
+}
+
+type DiffTimeConfig struct {
+	Before    string
+	After     string
+	Reverse   bool
+	Templates bool
+}
+
+type OTXConfig struct {
+	Host     string `mapstructure:"host"`
+	APIKey   string `mapstructure:"apikey"`
+	PageSize uint   `mapstructure:"pagesize"`
+}
+
+type WaybackConfig struct {
+	Host        string `mapstructure:"host"`
+	Collapse    string `mapstructure:"collapse"`
+	Pagination  string `mapstructure:"pagination"`
+	Concurrency uint   `mapstructure:"concurrency"`
+}
+
+type CommonCrawlConfig struct {
+	Concurrency uint     `mapstructure:"concurrency"`
+	Collections []string `mapstructure:"collections"`
+	CollInfo    string   `mapstructure:"collinfo"`
+	CacheTTL    uint     `mapstructure:"cachettl"`
+}
+
+type CDXConfig struct {
+	URL         string            `mapstructure:"url"`
+	Headers     map[string]string `mapstructure:"headers"`
+	Fields      map[string]string `mapstructure:"fields"`
+	Collapse    string            `mapstructure:"collapse"`
+	Pagination  string            `mapstructure:"pagination"`
+	Concurrency uint              `mapstructure:"concurrency"`
+}
+
+type MementoConfig struct {
+	TimeMap string `mapstructure:"timemap"`
+	// Prefix defaults to true when unset
+	Prefix *bool `mapstructure:"prefix"`
+}
+
+type VirusTotalConfig struct {
+	Host      string   `mapstructure:"host"`
+	APIKeys   []string `mapstructure:"apikeys"`
+	RateLimit uint     `mapstructure:"ratelimit"`
+}
+
+type URLhausConfig struct {
 	Host   string `mapstructure:"host"`
 	APIKey string `mapstructure:"apikey"`
 }
 
+type CCIndexConfig struct {
+	Dirs []string `mapstructure:"dirs"`
+}
+
+type WARCConfig struct {
+	Paths []string `mapstructure:"paths"`
+}
+
+type FileConfig struct {
+	Paths []string `mapstructure:"paths"`
+}
+
+type PluginConfig struct {
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+	Env     []string `mapstructure:"env"`
+	Timeout uint     `mapstructure:"timeout"`
+}
+
+type CrtShConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Host    string `mapstructure:"host"`
+}
+
+type ParamsConfig struct {
+	Scope   string   `mapstructure:"scope"`
+	Sources []string `mapstructure:"sources"`
+}
+
+type ValuesConfig struct {
+	Max uint   `mapstructure:"max"`
+	Dir string `mapstructure:"dir"`
+}
+
+type PathsConfig struct {
+	Types    []string `mapstructure:"types"`
+	MinCount uint     `mapstructure:"mincount"`
+	MaxDepth uint     `mapstructure:"maxdepth"`
+	Merge    bool     `mapstructure:"merge"`
+}
+
+type CategoryConfig struct {
+	Extensions []string `mapstructure:"extensions"`
+	Paths      []string `mapstructure:"paths"`
+	MimeTypes  []string `mapstructure:"mimetypes"`
+	Params     []string `mapstructure:"params"`
+}
+
+type CategoriesConfig struct {
+	Enabled bool                      `mapstructure:"enabled"`
+	Match   []string                  `mapstructure:"match"`
+	Dir     string                    `mapstructure:"dir"`
+	Rules   map[string]CategoryConfig `mapstructure:"rules"`
+}
+
+type PatternsConfig struct {
+	Match []string `mapstructure:"match"`
+	Only  bool     `mapstructure:"only"`
+	Dirs  []string `mapstructure:"dirs"`
+}
+
 type Config struct {
-	Filters           providers.Filters `mapstructure:"filters"`
-	Proxy             string            `mapstructure:"proxy"`
-	Threads           uint              `mapstructure:"threads"`
-	Timeout           uint              `mapstructure:"timeout"`
-	Verbose           bool              `mapstructure:"verbose"`
-	MaxRetries        uint              `mapstructure:"retries"`
-	IncludeSubdomains bool              `mapstructure:"subdomains"`
-	RemoveParameters  bool              `mapstructure:"parameters"`
-	Providers         []string          `mapstructure:"providers"`
-	Blacklist         []string          `mapstructure:"blacklist"`
-	JSON              bool              `mapstructure:"json"`
-	URLScan           URLScanConfig     `mapstructure:"urlscan"`
-	OTX               string            `mapstructure:"otx"`
-	Outfile           string            // output file to write to
+	Filters           providers.Filters       `mapstructure:"filters"`
+	Proxy             string                  `mapstructure:"proxy"`
+	Threads           uint                    `mapstructure:"threads"`
+	Timeout           uint                    `mapstructure:"timeout"`
+	Verbose           bool                    `mapstructure:"verbose"`
+	MaxRetries        uint                    `mapstructure:"retries"`
+	IncludeSubdomains bool                    `mapstructure:"subdomains"`
+	RemoveParameters  bool                    `mapstructure:"parameters"`
+	Providers         []string                `mapstructure:"providers"`
+	Blacklist         []string                `mapstructure:"blacklist"`
+	JSON              bool                    `mapstructure:"json"`
+	OutputMode        string                  `mapstructure:"outputmode"`
+	Origins           bool                    `mapstructure:"origins"`
+	HostCounts        bool                    `mapstructure:"hostcounts"`
+	Params            ParamsConfig            `mapstructure:"params"`
+	Values            ValuesConfig            `mapstructure:"values"`
+	Paths             PathsConfig             `mapstructure:"paths"`
+	URLScan           URLScanConfig           `mapstructure:"urlscan"`
+	Wayback           WaybackConfig           `mapstructure:"wayback"`
+	CommonCrawl       CommonCrawlConfig       `mapstructure:"commoncrawl"`
+	CDX               map[string]CDXConfig    `mapstructure:"cdx"`
+	Ordered           bool                    `mapstructure:"ordered"`
+	OTX               OTXConfig               `mapstructure:"otx"`
+	Memento           MementoConfig           `mapstructure:"memento"`
+	VirusTotal        VirusTotalConfig        `mapstructure:"virustotal"`
+	URLhaus           URLhausConfig           `mapstructure:"urlhaus"`
+	CCIndex           CCIndexConfig           `mapstructure:"ccindex"`
+	WARC              WARCConfig              `mapstructure:"warc"`
+	File              FileConfig              `mapstructure:"file"`
+	Plugins           map[string]PluginConfig `mapstructure:"plugins"`
+	CrtSh             CrtShConfig             `mapstructure:"crtsh"`
+	Categories        CategoriesConfig        `mapstructure:"categories"`
+	Patterns          PatternsConfig          `mapstructure:"patterns"`
+	Outfile           string                  // output file to write to
+	DiffTime          DiffTimeConfig          // options for the diff-time mode
 }
 
 func (c *Config) ProviderConfig() (*providers.Config, error) {
 	var dialer fasthttp.DialFunc
 
+	if c.Wayback.Collapse != "" && c.Wayback.Collapse != "none" && !collapseRegex.MatchString(c.Wayback.Collapse) {
+		return nil, fmt.Errorf("invalid wayback collapse strategy: %s", c.Wayback.Collapse)
+	}
+
+	for _, selector := range c.CommonCrawl.Collections {
+		if !commoncrawl.ValidSelector(selector) {
+			return nil, fmt.Errorf("invalid commoncrawl collection: %s", selector)
+		}
+	}
+
+	switch c.Wayback.Pagination {
+	case "", wayback.PaginationResumeKey, wayback.PaginationPage:
+	default:
+		return nil, fmt.Errorf("invalid wayback pagination: %s", c.Wayback.Pagination)
+	}
+
+	switch c.OutputMode {
+	case "", output.ModeURLs, output.ModeHosts, output.ModeParams, output.ModeValues, output.ModePaths:
+	default:
+		return nil, fmt.Errorf("invalid output mode: %s", c.OutputMode)
+	}
+
+	switch c.Params.Scope {
+	case "", output.ScopeGlobal, output.ScopeHost:
+	default:
+		return nil, fmt.Errorf("invalid params scope: %s", c.Params.Scope)
+	}
+
+	paramsSources := c.Params.Sources
+	if len(paramsSources) == 0 {
+		paramsSources = []string{output.ParamsQuery}
+	}
+	for _, source := range paramsSources {
+		switch source {
+		case output.ParamsQuery, output.ParamsFragment:
+		case output.ParamsPath:
+			// path parameters carry names alone, there are no values to collect
+			if c.OutputMode == output.ModeValues {
+				return nil, errors.New("values mode can't read parameters from the path, use query or fragment")
+			}
+		default:
+			return nil, fmt.Errorf("invalid params source: %s", source)
+		}
+	}
+
+	pathTypes := c.Paths.Types
+	if len(pathTypes) == 0 {
+		pathTypes = []string{output.PathDirs, output.PathFiles, output.PathFull}
+	}
+	for _, typ := range pathTypes {
+		switch typ {
+		case output.PathDirs, output.PathFiles, output.PathFull:
+		default:
+			return nil, fmt.Errorf("invalid path type: %s", typ)
+		}
+	}
+
+	if c.Patterns.Only && len(c.Patterns.Match) == 0 {
+		return nil, errors.New("only-matches requires pattern packs to match, set with --match-patterns")
+	}
+
+	cdx, err := c.cdxInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := c.plugins()
+	if err != nil {
+		return nil, err
+	}
+
 	if c.Proxy != "" {
 		parse, err := url.Parse(c.Proxy)
 		if err != nil {
@@ -72,25 +290,188 @@ func (c *Config) ProviderConfig() (*providers.Config, error) {
 			},
 			Dial: dialer,
 		},
-		Providers: c.Providers,
-		Output:    c.Outfile,
-		JSON:      c.JSON,
+		Providers:     c.Providers,
+		Output:        c.Outfile,
+		JSON:          c.JSON,
+		OutputMode:    c.OutputMode,
+		Origins:       c.Origins,
+		HostCounts:    c.HostCounts,
+		ParamsScope:   c.Params.Scope,
+		ParamsSources: paramsSources,
+		ValuesMax:     c.Values.Max,
+		ValuesDir:     c.Values.Dir,
+		PathTypes:     pathTypes,
+		PathsMinCount: c.Paths.MinCount,
+		PathsMaxDepth: c.Paths.MaxDepth,
+		PathsMerge:    c.Paths.Merge,
 		URLScan: providers.URLScan{
-			Host:   c.URLScan.Host,
-			APIKey: c.URLScan.APIKey,
+			Host:     c.URLScan.Host,
+			APIKey:   c.URLScan.APIKey,
+			MaxWait:  c.URLScan.MaxWait,
+			Query:    c.URLScan.Query,
+			PageSize: c.URLScan.PageSize,
+		},
+		Wayback: providers.Wayback{
+			Host:        c.Wayback.Host,
+			Collapse:    c.Wayback.Collapse,
+			Pagination:  c.Wayback.Pagination,
+			Concurrency: c.Wayback.Concurrency,
+		},
+		CommonCrawl: providers.CommonCrawl{
+			Concurrency: c.CommonCrawl.Concurrency,
+			Collections: c.CommonCrawl.Collections,
+			CollInfo:    c.CommonCrawl.CollInfo,
+			CacheTTL:    c.CommonCrawl.CacheTTL,
+		},
+		CDX:     cdx,
+		Ordered: c.Ordered,
+		OTX: providers.OTX{
+			Host:     c.OTX.Host,
+			APIKey:   c.OTX.APIKey,
+			PageSize: c.OTX.PageSize,
+		},
+		Memento: providers.Memento{
+			TimeMap: c.Memento.TimeMap,
+			Prefix:  c.Memento.Prefix == nil || *c.Memento.Prefix,
+		},
+		VirusTotal: providers.VirusTotal{
+			Host:      c.VirusTotal.Host,
+			APIKeys:   c.VirusTotal.APIKeys,
+			RateLimit: c.VirusTotal.RateLimit,
+		},
+		URLhaus: providers.URLhaus{
+			Host:   c.URLhaus.Host,
+			APIKey: c.URLhaus.APIKey,
+		},
+		CCIndex: providers.CCIndex{
+			Dirs: c.CCIndex.Dirs,
+		},
+		WARC: providers.WARC{
+			Paths: c.WARC.Paths,
+		},
+		File: providers.File{
+			Paths: c.File.Paths,
+		},
+		Plugins: plugins,
+		Classify: providers.Classify{
+			Enabled: c.Categories.Enabled || len(c.Categories.Match) > 0 || c.Categories.Dir != "",
+			Match:   c.Categories.Match,
+			Dir:     c.Categories.Dir,
+			Rules:   categoryRules(c.Categories.Rules),
+		},
+		Patterns: providers.Patterns{
+			Match: c.Patterns.Match,
+			Only:  c.Patterns.Only,
+			Dirs:  c.Patterns.Dirs,
+		},
+		CrtSh: providers.CrtSh{
+			Enabled: c.CrtSh.Enabled,
+			Host:    c.CrtSh.Host,
 		},
-		OTX: c.OTX,
 	}
 
 	log.SetLevel(log.ErrorLevel)
 	if c.Verbose {
 		log.SetLevel(log.InfoLevel)
 	}
-	pc.Blacklist = mapset.NewThreadUnsafeSet(c.Blacklist...)
-	pc.Blacklist.Add("")
+	pc.Blacklist = mapset.NewThreadUnsafeSet[string]("")
+	for _, ext := range c.Blacklist {
+		pc.Blacklist.Add(strings.ToLower(ext))
+	}
 	return pc, nil
 }
 
+// builtinProviders are the names cdx instances can't use
+var builtinProviders = map[string]bool{
+	"wayback":     true,
+	"commoncrawl": true,
+	"otx":         true,
+	"urlscan":     true,
+	"memento":     true,
+	"virustotal":  true,
+	"urlhaus":     true,
+	"ccindex":     true,
+	"warc":        true,
+	"file":        true,
+}
+
+// cdxInstances validates the configured cdx instances
+func (c *Config) cdxInstances() (map[string]providers.CDX, error) {
+	instances := make(map[string]providers.CDX, len(c.CDX))
+	for name, inst := range c.CDX {
+		if builtinProviders[name] {
+			return nil, fmt.Errorf("cdx instance %s conflicts with a built-in provider", name)
+		}
+		if inst.URL == "" {
+			return nil, fmt.Errorf("cdx instance %s: missing url", name)
+		}
+		if inst.Collapse != "" && inst.Collapse != "none" && !collapseRegex.MatchString(inst.Collapse) {
+			return nil, fmt.Errorf("cdx instance %s: invalid collapse strategy: %s", name, inst.Collapse)
+		}
+		switch inst.Pagination {
+		case "", wayback.PaginationResumeKey, wayback.PaginationPage, wayback.PaginationNone:
+		default:
+			return nil, fmt.Errorf("cdx instance %s: invalid pagination: %s", name, inst.Pagination)
+		}
+		for field := range inst.Fields {
+			if _, ok := wayback.DefaultFields[field]; !ok {
+				return nil, fmt.Errorf("cdx instance %s: unknown field: %s", name, field)
+			}
+		}
+		instances[name] = providers.CDX{
+			URL:         inst.URL,
+			Headers:     inst.Headers,
+			Fields:      inst.Fields,
+			Collapse:    inst.Collapse,
+			Pagination:  inst.Pagination,
+			Concurrency: inst.Concurrency,
+		}
+	}
+	return instances, nil
+}
+
+// plugins validates the configured plugins
+func (c *Config) plugins() (map[string]providers.Plugin, error) {
+	plugins := make(map[string]providers.Plugin, len(c.Plugins))
+	for name, p := range c.Plugins {
+		if builtinProviders[name] {
+			return nil, fmt.Errorf("plugin %s conflicts with a built-in provider", name)
+		}
+		if _, ok := c.CDX[name]; ok {
+			return nil, fmt.Errorf("plugin %s conflicts with a cdx instance", name)
+		}
+		if p.Command == "" {
+			return nil, fmt.Errorf("plugin %s: missing command", name)
+		}
+		for _, env := range p.Env {
+			if !strings.Contains(env, "=") {
+				return nil, fmt.Errorf("plugin %s: invalid env %q, expected KEY=value", name, env)
+			}
+		}
+		plugins[name] = providers.Plugin{
+			Command: p.Command,
+			Args:    p.Args,
+			Env:     p.Env,
+			Timeout: p.Timeout,
+		}
+	}
+	return plugins, nil
+}
+
+// categoryRules converts the configured category rules
+func categoryRules(rules map[string]CategoryConfig) map[string]providers.Category {
+	categories := make(map[string]providers.Category, len(rules))
+	for name, rule := range rules {
+		categories[name] = providers.Category{
+			Extensions: rule.Extensions,
+			Paths:      rule.Paths,
+			MimeTypes:  rule.MimeTypes,
+			Params:     rule.Params,
+		}
+	}
+	return categories
+}
+
 type Options struct {
 	viper *viper.Viper
 }
@@ -105,11 +486,32 @@ func New() *Options {
 	pflag.Uint("retries", 0, "retries for HTTP client")
 	pflag.String("proxy", "", "http proxy to use")
 	pflag.StringSlice("blacklist", []string{}, "list of extensions to skip")
-	pflag.StringSlice("providers", []string{}, "list of providers to use (wayback,commoncrawl,otx,urlscan)")
+	pflag.StringSlice("providers", []string{}, "list of providers to use (wayback,commoncrawl,otx,urlscan,memento,virustotal,urlhaus,ccindex,warc,file or a configured cdx instance or plugin)")
+	pflag.StringSlice("collections", []string{}, "commoncrawl collections to search (latest, latest:N, all, range or ids like CC-MAIN-2023-50)")
+	pflag.String("urlscan-query", "", "additional urlscan search query (e.g. page.status:200 AND date:>now-30d)")
 	pflag.Bool("subs", false, "include subdomains of target domain")
+	pflag.Bool("expand-subs", false, "also query the subdomains found in certificate transparency logs")
 	pflag.Bool("fp", false, "remove different parameters of the same endpoint")
 	pflag.Bool("verbose", false, "show verbose output")
 	pflag.Bool("json", false, "output as json")
+	pflag.String("output-mode", "", "what to output: urls, hosts, params, values or paths (default urls)")
+	pflag.Bool("origins", false, "hosts mode: output scheme://host:port instead of hostnames")
+	pflag.Bool("host-counts", false, "hosts mode: output the number of urls of each host, once all results were received")
+	pflag.String("params-scope", "", "params mode: count parameter names globally or per host (default global)")
+	pflag.StringSlice("params-from", []string{}, "params and values modes: where to read parameters from, replacing the default query (query,fragment and, in params mode, path)")
+	pflag.Uint("max-values", 0, "values mode: number of values kept per parameter of an endpoint (default 100)")
+	pflag.String("values-dir", "", "values mode: directory to write a wordlist per parameter to")
+	pflag.StringSlice("paths-types", []string{}, "paths mode: entries to output (dirs,files,paths)")
+	pflag.Uint("paths-min-count", 0, "paths mode: only output entries seen at least this many times")
+	pflag.Uint("paths-max-depth", 0, "paths mode: only count entries up to this many segments deep")
+	pflag.Bool("paths-merge", false, "paths mode: rank the entries of all domains in a single list")
+	pflag.Bool("classify", false, "tag results with categories such as js, api, admin or backup")
+	pflag.StringSlice("category", []string{}, "only output results in these categories (implies --classify)")
+	pflag.String("category-dir", "", "directory to write a list of urls per category to (implies --classify)")
+	pflag.StringSlice("match-patterns", []string{}, "pattern packs to match urls against, such as redirect,ssrf,lfi or all")
+	pflag.Bool("only-matches", false, "only output urls matching a pattern pack (requires --match-patterns)")
+	pflag.StringSlice("patterns-dir", []string{}, "directories of yaml or json pattern packs, replacing built-in packs of the same name")
+	pflag.Bool("ordered", false, "keep results in page order when pages are fetched in parallel")
 
 	// filter flags
 	pflag.StringSlice("mc", []string{}, "list of status codes to match")
@@ -120,6 +522,12 @@ func New() *Options {
 	pflag.String("to", "", "fetch urls to date (format: YYYYMM)")
 	pflag.Bool("version", false, "show gau version")
 
+	// diff-time flags
+	pflag.String("before", "", "diff-time: end of the earlier window (format: YYYY or YYYYMM)")
+	pflag.String("after", "", "diff-time: start of the later window (format: YYYY or YYYYMM)")
+	pflag.Bool("reverse", false, "diff-time: report urls only present in the later window")
+	pflag.Bool("templates", false, "diff-time: compare path templates instead of full urls")
+
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
 
@@ -162,7 +570,12 @@ func (o *Options) ReadConfigFile(name string) (*Config, error) {
 
 	var c Config
 
-	if err := o.viper.Unmarshal(&c); err != nil {
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		legacyOTXHook,
+	))
+	if err := o.viper.Unmarshal(&c, decodeHook); err != nil {
 		return o.DefaultConfig(), err
 	}
 
@@ -171,6 +584,15 @@ func (o *Options) ReadConfigFile(name string) (*Config, error) {
 	return &c, nil
 }
 
+// legacyOTXHook decodes the former otx = "<url>" setting into the host of the [otx] section
+func legacyOTXHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(OTXConfig{}) {
+		return data, nil
+	}
+	log.Warnf("otx = %q is deprecated, set host in the [otx] section instead", data)
+	return map[string]interface{}{"host": data}, nil
+}
+
 func (o *Options) DefaultConfig() *Config {
 	c := &Config{
 		Filters:           providers.Filters{},
@@ -204,6 +626,27 @@ func (o *Options) getFlagValues(c *Config) {
 	blacklist := o.viper.GetStringSlice("blacklist")
 	subs := o.viper.GetBool("subs")
 	fp := o.viper.GetBool("fp")
+	ordered := o.viper.GetBool("ordered")
+	expandSubs := o.viper.GetBool("expand-subs")
+	outputMode := o.viper.GetString("output-mode")
+	origins := o.viper.GetBool("origins")
+	hostCounts := o.viper.GetBool("host-counts")
+	paramsScope := o.viper.GetString("params-scope")
+	paramsFrom := o.viper.GetStringSlice("params-from")
+	maxValues := o.viper.GetUint("max-values")
+	valuesDir := o.viper.GetString("values-dir")
+	pathTypes := o.viper.GetStringSlice("paths-types")
+	minCount := o.viper.GetUint("paths-min-count")
+	maxDepth := o.viper.GetUint("paths-max-depth")
+	merge := o.viper.GetBool("paths-merge")
+	classify := o.viper.GetBool("classify")
+	category := o.viper.GetStringSlice("category")
+	categoryDir := o.viper.GetString("category-dir")
+	matchPatterns := o.viper.GetStringSlice("match-patterns")
+	onlyMatches := o.viper.GetBool("only-matches")
+	patternsDir := o.viper.GetStringSlice("patterns-dir")
+	collections := o.viper.GetStringSlice("collections")
+	urlscanQuery := o.viper.GetString("urlscan-query")
 
 	if version {
 		fmt.Printf("gau version: %s\n", providers.Version)
@@ -244,6 +687,95 @@ func (o *Options) getFlagValues(c *Config) {
 		c.RemoveParameters = fp
 	}
 
+	if ordered {
+		c.Ordered = ordered
+	}
+
+	if expandSubs {
+		c.CrtSh.Enabled = expandSubs
+	}
+
+	if outputMode != "" {
+		c.OutputMode = outputMode
+	}
+
+	if origins {
+		c.Origins = origins
+	}
+
+	if hostCounts {
+		c.HostCounts = hostCounts
+	}
+
+	if paramsScope != "" {
+		c.Params.Scope = paramsScope
+	}
+
+	// set if --params-from flag is specified, otherwise use default
+	if len(paramsFrom) > 0 {
+		c.Params.Sources = paramsFrom
+	}
+
+	if maxValues > 0 {
+		c.Values.Max = maxValues
+	}
+
+	if valuesDir != "" {
+		c.Values.Dir = valuesDir
+	}
+
+	// set if --paths-types flag is specified, otherwise use default
+	if len(pathTypes) > 0 {
+		c.Paths.Types = pathTypes
+	}
+
+	if minCount > 0 {
+		c.Paths.MinCount = minCount
+	}
+
+	if maxDepth > 0 {
+		c.Paths.MaxDepth = maxDepth
+	}
+
+	if merge {
+		c.Paths.Merge = merge
+	}
+
+	if classify {
+		c.Categories.Enabled = classify
+	}
+
+	// set if --category flag is specified, otherwise use default
+	if len(category) > 0 {
+		c.Categories.Match = category
+	}
+
+	if categoryDir != "" {
+		c.Categories.Dir = categoryDir
+	}
+
+	// set if --match-patterns flag is specified, otherwise use default
+	if len(matchPatterns) > 0 {
+		c.Patterns.Match = matchPatterns
+	}
+
+	if onlyMatches {
+		c.Patterns.Only = onlyMatches
+	}
+
+	if len(patternsDir) > 0 {
+		c.Patterns.Dirs = patternsDir
+	}
+
+	if urlscanQuery != "" {
+		c.URLScan.Query = urlscanQuery
+	}
+
+	// set if --collections flag is specified, otherwise use default
+	if len(collections) > 0 {
+		c.CommonCrawl.Collections = collections
+	}
+
 	c.JSON = json
 	c.Verbose = verbose
 
@@ -295,4 +827,11 @@ func (o *Options) getFlagValues(c *Config) {
 	if seenFilterFlag {
 		c.Filters = filters
 	}
+
+	c.DiffTime = DiffTimeConfig{
+		Before:    o.viper.GetString("before"),
+		After:     o.viper.GetString("after"),
+		Reverse:   o.viper.GetBool("reverse"),
+		Templates: o.viper.GetBool("templates"),
+	}
 }
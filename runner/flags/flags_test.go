@@ -0,0 +1,26 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/lc/gau/v2/pkg/output"
+)
+
+func TestParamsSources(t *testing.T) {
+	tests := []struct {
+		mode    string
+		sources []string
+		wantErr bool
+	}{
+		{mode: output.ModeParams, sources: []string{"query", "fragment", "path"}},
+		{mode: output.ModeValues, sources: []string{"query", "fragment"}},
+		{mode: output.ModeValues, sources: []string{"query", "path"}, wantErr: true},
+		{mode: output.ModeParams, sources: []string{"cookie"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		c := &Config{OutputMode: tt.mode, Params: ParamsConfig{Sources: tt.sources}}
+		if _, err := c.ProviderConfig(); (err != nil) != tt.wantErr {
+			t.Errorf("ProviderConfig() with %s mode and sources %v: error = %v, want error %v", tt.mode, tt.sources, err, tt.wantErr)
+		}
+	}
+}
@@ -2,13 +2,21 @@ package runner
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"strings"
 	"sync"
 
 	"github.com/lc/gau/v2/pkg/providers"
+	"github.com/lc/gau/v2/pkg/providers/ccindex"
 	"github.com/lc/gau/v2/pkg/providers/commoncrawl"
+	"github.com/lc/gau/v2/pkg/providers/file"
+	"github.com/lc/gau/v2/pkg/providers/memento"
 	"github.com/lc/gau/v2/pkg/providers/otx"
+	"github.com/lc/gau/v2/pkg/providers/plugin"
+	"github.com/lc/gau/v2/pkg/providers/urlhaus"
 	"github.com/lc/gau/v2/pkg/providers/urlscan"
+	"github.com/lc/gau/v2/pkg/providers/virustotal"
+	"github.com/lc/gau/v2/pkg/providers/warc"
 	"github.com/lc/gau/v2/pkg/providers/wayback"
 	"github.com/sirupsen/logrus"
 )
@@ -18,34 +26,73 @@ type Runner struct {
 
 	Providers []providers.Provider
 	threads   uint
-	ctx       context.Context
+	ordered   bool
+	// limits bounds the number of pages fetched at once for each paginated provider
+	limits map[string]chan struct{}
+	ctx    context.Context
 }
 
 // Init initializes the runner
 func (r *Runner) Init(c *providers.Config, providers []string, filters providers.Filters) error {
 	r.threads = c.Threads
+	r.ordered = c.Ordered
 	for _, name := range providers {
 		switch name {
 		case "urlscan":
-			r.Providers = append(r.Providers, urlscan.New(c))
+			r.Providers = append(r.Providers, urlscan.New(c, filters))
 		case "otx":
 			r.Providers = append(r.Providers, otx.New(c))
 		case "wayback":
 			r.Providers = append(r.Providers, wayback.New(c, filters))
 		case "commoncrawl":
-			cc, err := commoncrawl.New(c, filters)
-			if err != nil {
-				return fmt.Errorf("error instantiating commoncrawl: %v\n", err)
+			r.Providers = append(r.Providers, commoncrawl.New(c, filters))
+		case "memento":
+			r.Providers = append(r.Providers, memento.New(c, filters))
+		case "virustotal":
+			r.Providers = append(r.Providers, virustotal.New(c))
+		case "urlhaus":
+			r.Providers = append(r.Providers, urlhaus.New(c))
+		case "ccindex":
+			r.Providers = append(r.Providers, ccindex.New(c, filters))
+		case "warc":
+			r.Providers = append(r.Providers, warc.New(c, filters))
+		case "file":
+			r.Providers = append(r.Providers, file.New(c, filters))
+		default:
+			if cdx, ok := c.CDX[strings.ToLower(name)]; ok {
+				r.Providers = append(r.Providers, wayback.NewCDX(strings.ToLower(name), cdx, c, filters))
+			} else if p, ok := c.Plugins[strings.ToLower(name)]; ok {
+				r.Providers = append(r.Providers, plugin.New(strings.ToLower(name), p, c, filters))
 			}
-			r.Providers = append(r.Providers, cc)
 		}
 	}
 
+	r.initLimits()
 	return nil
 }
 
+// initLimits creates the page limiter of each paginated provider. A provider never
+// fetches more pages at once than there are threads.
+func (r *Runner) initLimits() {
+	r.limits = make(map[string]chan struct{})
+	for _, provider := range r.Providers {
+		p, ok := provider.(providers.Paginator)
+		if !ok {
+			continue
+		}
+		limit := p.MaxConcurrency()
+		if r.threads < limit {
+			limit = r.threads
+		}
+		if limit == 0 {
+			limit = 1
+		}
+		r.limits[p.Name()] = make(chan struct{}, limit)
+	}
+}
+
 // Starts starts the worker
-func (r *Runner) Start(ctx context.Context, workChan chan Work, results chan string) {
+func (r *Runner) Start(ctx context.Context, workChan chan Work, results chan providers.Result) {
 	for i := uint(0); i < r.threads; i++ {
 		r.Add(1)
 		go func() {
@@ -58,18 +105,33 @@ func (r *Runner) Start(ctx context.Context, workChan chan Work, results chan str
 type Work struct {
 	domain   string
 	provider providers.Provider
+	// exact restricts the query to the host, without its subdomains
+	exact bool
 }
 
+// NewWork returns work querying domain, lowercased as providers compare hosts in lowercase
 func NewWork(domain string, provider providers.Provider) Work {
-	return Work{domain, provider}
+	return Work{domain: strings.ToLower(domain), provider: provider}
 }
 
-func (w *Work) Do(ctx context.Context, results chan string) error {
-	return w.provider.Fetch(ctx, w.domain, results)
+// NewHostWork returns work querying host alone, even when subdomains are included
+func NewHostWork(host string, provider providers.Provider) Work {
+	return Work{domain: strings.ToLower(host), provider: provider, exact: true}
+}
+
+func (w *Work) Do(ctx context.Context, results chan providers.Result) error {
+	return w.provider.Fetch(w.context(ctx), w.domain, results)
+}
+
+func (w *Work) context(ctx context.Context) context.Context {
+	if w.exact {
+		return providers.WithExactHost(ctx)
+	}
+	return ctx
 }
 
 // worker checks to see if the context is finished and executes the fetching process for each provider
-func (r *Runner) worker(ctx context.Context, workChan chan Work, results chan string) {
+func (r *Runner) worker(ctx context.Context, workChan chan Work, results chan providers.Result) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -78,9 +140,139 @@ func (r *Runner) worker(ctx context.Context, workChan chan Work, results chan st
 			if !ok {
 				return
 			}
-			if err := work.Do(ctx, results); err != nil {
+			if err := r.do(ctx, work, results); err != nil {
 				logrus.WithField("provider", work.provider.Name()).Warnf("%s - %v", work.domain, err)
 			}
 		}
 	}
 }
+
+// do executes work, splitting it into pages when the provider supports it.
+func (r *Runner) do(ctx context.Context, work Work, results chan providers.Result) error {
+	p, ok := work.provider.(providers.Paginator)
+	if !ok {
+		return work.Do(ctx, results)
+	}
+
+	ctx = providers.WithPageState(work.context(ctx))
+	pages, err := p.Pages(ctx, work.domain)
+	if errors.Is(err, providers.ErrNotPaginated) {
+		return work.Do(ctx, results)
+	}
+	if err != nil {
+		return err
+	}
+	return r.fetchPages(ctx, p, work.domain, pages, results)
+}
+
+// fetchPages fetches every page of domain, bounded by the provider's page limiter.
+// If the runner is ordered, pages are buffered and sent in page order.
+func (r *Runner) fetchPages(ctx context.Context, p providers.Paginator, domain string, pages uint, results chan providers.Result) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	limit := r.limits[p.Name()]
+
+	var ordered *orderedPages
+	if r.ordered {
+		ordered = newOrderedPages(pages, results)
+	}
+
+	page := uint(0)
+dispatch:
+	for ; page < pages; page++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case limit <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(page uint) {
+			defer wg.Done()
+			defer func() { <-limit }()
+
+			var err error
+			if ordered != nil {
+				err = ordered.fetch(ctx, p, domain, page)
+			} else {
+				err = p.FetchPage(ctx, domain, page, results)
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(page)
+	}
+	wg.Wait()
+
+	if ordered != nil {
+		ordered.skip(page)
+		ordered.wait()
+	}
+	return errors.Join(errs...)
+}
+
+// orderedPages buffers the results of each page and emits them in page order.
+type orderedPages struct {
+	results [][]providers.Result
+	done    []chan struct{}
+	emitted chan struct{}
+}
+
+func newOrderedPages(pages uint, results chan providers.Result) *orderedPages {
+	o := &orderedPages{
+		results: make([][]providers.Result, pages),
+		done:    make([]chan struct{}, pages),
+		emitted: make(chan struct{}),
+	}
+	for i := range o.done {
+		o.done[i] = make(chan struct{})
+	}
+
+	go func() {
+		defer close(o.emitted)
+		for i, done := range o.done {
+			<-done
+			for _, result := range o.results[i] {
+				results <- result
+			}
+			o.results[i] = nil
+		}
+	}()
+	return o
+}
+
+// fetch fetches page into its buffer
+func (o *orderedPages) fetch(ctx context.Context, p providers.Paginator, domain string, page uint) error {
+	defer close(o.done[page])
+
+	out := make(chan providers.Result)
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for result := range out {
+			o.results[page] = append(o.results[page], result)
+		}
+	}()
+
+	err := p.FetchPage(ctx, domain, page, out)
+	close(out)
+	<-drained
+	return err
+}
+
+// skip marks the pages from page onwards as done, used when fetching was cancelled
+func (o *orderedPages) skip(page uint) {
+	for ; page < uint(len(o.done)); page++ {
+		close(o.done[page])
+	}
+}
+
+// wait blocks until every page has been emitted
+func (o *orderedPages) wait() {
+	<-o.emitted
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lc/gau/v2/pkg/providers"
+)
+
+// slowPages is a paginator whose first pages take the longest to fetch
+type slowPages struct {
+	pages uint
+	fail  uint
+}
+
+func (s *slowPages) Name() string { return "slow" }
+
+func (s *slowPages) Fetch(ctx context.Context, domain string, results chan providers.Result) error {
+	return nil
+}
+
+func (s *slowPages) Pages(ctx context.Context, domain string) (uint, error) {
+	return s.pages, nil
+}
+
+func (s *slowPages) FetchPage(ctx context.Context, domain string, page uint, results chan providers.Result) error {
+	time.Sleep(time.Duration(s.pages-page) * 10 * time.Millisecond)
+	if page == s.fail {
+		return fmt.Errorf("page %d failed", page)
+	}
+	for i := 0; i < 2; i++ {
+		results <- providers.Result{URL: fmt.Sprintf("https://%s/%d/%d", domain, page, i)}
+	}
+	return nil
+}
+
+func (s *slowPages) MaxConcurrency() uint { return 4 }
+
+func TestFetchPagesOrdered(t *testing.T) {
+	p := &slowPages{pages: 4, fail: 2}
+	r := &Runner{Providers: []providers.Provider{p}, threads: 4, ordered: true}
+	r.initLimits()
+
+	results := make(chan providers.Result, 10)
+	err := r.fetchPages(context.Background(), p, "example.com", p.pages, results)
+	close(results)
+	if err == nil || err.Error() != "page 2 failed" {
+		t.Errorf("fetchPages() error = %v, want the error of page 2", err)
+	}
+
+	var got []string
+	for result := range results {
+		got = append(got, result.URL)
+	}
+	want := []string{
+		"https://example.com/0/0", "https://example.com/0/1",
+		"https://example.com/1/0", "https://example.com/1/1",
+		"https://example.com/3/0", "https://example.com/3/1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fetchPages() sent %v, want %v", got, want)
+	}
+}
+
+func TestFetchPagesCanceled(t *testing.T) {
+	p := &slowPages{pages: 4, fail: 4}
+	// a single page at a time, so the later pages are never dispatched
+	r := &Runner{Providers: []providers.Provider{p}, threads: 1, ordered: true}
+	r.initLimits()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := make(chan providers.Result, 10)
+	done := make(chan error)
+	go func() {
+		done <- r.fetchPages(ctx, p, "example.com", p.pages, results)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("fetchPages() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetchPages() did not return once canceled")
+	}
+}